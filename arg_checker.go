@@ -3,21 +3,46 @@ package promptx
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // CheckFunc 参数检查函数类型
 type CheckFunc func(value string) error
 
+// CheckBuilder 带参数的检查器构造函数类型
+// param 为检查器名称后括号内的原始文本, 例如 Range(1,100) 的 param 为 "1,100"
+type CheckBuilder func(param string) (CheckFunc, error)
+
 var (
 	// checkers 注册的检查器
 	checkers = make(map[string]CheckFunc)
+	// checkBuilders 注册的带参数检查器
+	checkBuilders = make(map[string]CheckBuilder)
+
+	// compiledChecks 已经解析过的 check tag
+	compiledChecks   = make(map[string]CheckFunc)
+	compiledChecksMu sync.Mutex
 )
 
 // RegisterChecker 注册检查器
 func RegisterChecker(name string, fn CheckFunc) {
 	checkers[name] = fn
+	resetCompiledChecks()
+}
+
+// RegisterCheckBuilder 注册带参数的检查器, 在 check tag 中使用 Name(param) 调用
+func RegisterCheckBuilder(name string, builder CheckBuilder) {
+	checkBuilders[name] = builder
+	resetCompiledChecks()
 }
 
 func init() {
@@ -28,6 +53,128 @@ func init() {
 	RegisterChecker("Float", CheckerFloat)
 	RegisterChecker("NotZeroInteger", CheckerNotZeroInteger)
 	RegisterChecker("NaturalNumber", CheckerNaturalNumber)
+	RegisterChecker("Email", CheckerEmail)
+	RegisterChecker("Hostname", CheckerHostname)
+	RegisterChecker("URL", CheckerURL)
+	RegisterChecker("IP", CheckerIP)
+	RegisterChecker("CIDR", CheckerCIDR)
+	RegisterChecker("FileExists", CheckerFileExists)
+	RegisterChecker("DirExists", CheckerDirExists)
+	// 注册内置带参数检查器
+	RegisterCheckBuilder("Range", buildRangeChecker)
+	RegisterCheckBuilder("Len", buildLenChecker)
+	RegisterCheckBuilder("Regex", CheckerRegex)
+	RegisterCheckBuilder("OneOf", buildOneOfChecker)
+}
+
+// resetCompiledChecks 注册新的检查器后清空缓存
+func resetCompiledChecks() {
+	compiledChecksMu.Lock()
+	defer compiledChecksMu.Unlock()
+	compiledChecks = make(map[string]CheckFunc)
+}
+
+// compileCheck 解析 check tag, 返回组合后的检查函数
+//
+// 格式: 多个检查规则使用 ; 分隔, 每个规则为 Name 或者 Name(param).
+// 例如: check:"NotEmpty;Len(3,16);Regex(^[a-z]+$)"
+// 检查失败时返回的错误包含失败的规则.
+func compileCheck(tag string) (CheckFunc, error) {
+	compiledChecksMu.Lock()
+	fn, ok := compiledChecks[tag]
+	compiledChecksMu.Unlock()
+	if ok {
+		return fn, nil
+	}
+
+	type rule struct {
+		text string
+		fn   CheckFunc
+	}
+	var rules []rule
+	for _, text := range splitCheckRules(tag) {
+		name, param, hasParam := parseCheckRule(text)
+		var fn CheckFunc
+		if hasParam {
+			builder, ok := checkBuilders[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown checker %s", text)
+			}
+			var err error
+			if fn, err = builder(param); err != nil {
+				return nil, fmt.Errorf("invalid checker %s: %v", text, err)
+			}
+		} else if fn, ok = checkers[name]; !ok {
+			return nil, fmt.Errorf("unknown checker %s", text)
+		}
+		rules = append(rules, rule{text: text, fn: fn})
+	}
+
+	fn = func(value string) error {
+		for _, r := range rules {
+			if err := r.fn(value); err != nil {
+				return fmt.Errorf("%s: %v", r.text, err)
+			}
+		}
+		return nil
+	}
+	compiledChecksMu.Lock()
+	compiledChecks[tag] = fn
+	compiledChecksMu.Unlock()
+	return fn, nil
+}
+
+// splitCheckRules 使用 ; 分隔检查规则, 忽略括号内以及转义的 ;
+func splitCheckRules(tag string) (rules []string) {
+	depth, start := 0, 0
+	escaped := false
+	for i, r := range tag {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+		case r == ';' && depth == 0:
+			rules = append(rules, tag[start:i])
+			start = i + 1
+		}
+	}
+	rules = append(rules, tag[start:])
+
+	result := rules[:0]
+	for _, rule := range rules {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// parseCheckRule 解析单个检查规则 Name(param)
+func parseCheckRule(text string) (name, param string, hasParam bool) {
+	idx := strings.IndexByte(text, '(')
+	if idx < 0 || !strings.HasSuffix(text, ")") {
+		return text, "", false
+	}
+	return strings.TrimSpace(text[:idx]), text[idx+1 : len(text)-1], true
+}
+
+// splitCheckParams 使用逗号分隔检查器参数
+func splitCheckParams(param string, n int) ([]string, error) {
+	params := strings.Split(param, ",")
+	if len(params) > n {
+		return nil, fmt.Errorf("too many params, expect %d got %d", n, len(params))
+	}
+	for i := range params {
+		params[i] = strings.TrimSpace(params[i])
+	}
+	return params, nil
 }
 
 // CheckerNotEmpty 检查非空
@@ -91,3 +238,174 @@ func CheckerNaturalNumber(value string) error {
 	}
 	return nil
 }
+
+// CheckerEmail 检查邮箱地址
+func CheckerEmail(value string) error {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return fmt.Errorf("%q is not a valid email address", value)
+	}
+	return nil
+}
+
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+
+// CheckerHostname 检查主机名（RFC 1123）
+func CheckerHostname(value string) error {
+	if len(value) > 253 || !hostnameRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not a valid hostname", value)
+	}
+	return nil
+}
+
+// CheckerURL 检查 URL, 必须包含 scheme 和 host
+func CheckerURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", value)
+	}
+	return nil
+}
+
+// CheckerIP 检查 IP 地址
+func CheckerIP(value string) error {
+	if net.ParseIP(value) == nil {
+		return fmt.Errorf("%q is not a valid IP address", value)
+	}
+	return nil
+}
+
+// CheckerCIDR 检查 CIDR 格式的网段
+func CheckerCIDR(value string) error {
+	_, _, err := net.ParseCIDR(value)
+	return err
+}
+
+// CheckerFileExists 检查文件存在
+func CheckerFileExists(value string) error {
+	info, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", value)
+	}
+	return nil
+}
+
+// CheckerDirExists 检查目录存在
+func CheckerDirExists(value string) error {
+	info, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", value)
+	}
+	return nil
+}
+
+// CheckerRange 检查数值范围 [min, max]
+func CheckerRange(min, max float64) CheckFunc {
+	return func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		if v < min || v > max {
+			return fmt.Errorf("%s out of range [%v, %v]", value, min, max)
+		}
+		return nil
+	}
+}
+
+// buildRangeChecker Range(min,max) 省略的边界不做限制, 例如 Range(1,)
+func buildRangeChecker(param string) (CheckFunc, error) {
+	params, err := splitCheckParams(param, 2)
+	if err != nil {
+		return nil, err
+	}
+	bounds := []float64{math.Inf(-1), math.Inf(1)}
+	for i, p := range params {
+		if p == "" {
+			continue
+		}
+		if bounds[i], err = strconv.ParseFloat(p, 64); err != nil {
+			return nil, err
+		}
+	}
+	return CheckerRange(bounds[0], bounds[1]), nil
+}
+
+// CheckerLen 检查字符长度 [min, max], max 小于 0 表示不限制
+func CheckerLen(min, max int) CheckFunc {
+	return func(value string) error {
+		n := utf8.RuneCountInString(value)
+		if n < min {
+			return fmt.Errorf("length %d less than %d", n, min)
+		}
+		if max >= 0 && n > max {
+			return fmt.Errorf("length %d greater than %d", n, max)
+		}
+		return nil
+	}
+}
+
+// buildLenChecker Len(n) 长度必须为 n, Len(min,max) 长度范围, 省略的边界不做限制
+func buildLenChecker(param string) (CheckFunc, error) {
+	params, err := splitCheckParams(param, 2)
+	if err != nil {
+		return nil, err
+	}
+	bounds := []int{0, -1}
+	for i, p := range params {
+		if p == "" {
+			continue
+		}
+		if bounds[i], err = strconv.Atoi(p); err != nil {
+			return nil, err
+		}
+	}
+	if len(params) == 1 {
+		bounds[1] = bounds[0]
+	}
+	return CheckerLen(bounds[0], bounds[1]), nil
+}
+
+// CheckerRegex 检查是否匹配正则表达式
+func CheckerRegex(expr string) (CheckFunc, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("%q not match %s", value, expr)
+		}
+		return nil
+	}, nil
+}
+
+// CheckerOneOf 检查是否为选项之一
+func CheckerOneOf(options ...string) CheckFunc {
+	return func(value string) error {
+		for _, v := range options {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q not in [%s]", value, strings.Join(options, "|"))
+	}
+}
+
+// buildOneOfChecker OneOf(a|b|c)
+func buildOneOfChecker(param string) (CheckFunc, error) {
+	options := strings.Split(param, "|")
+	for i := range options {
+		options[i] = strings.TrimSpace(options[i])
+	}
+	return CheckerOneOf(options...), nil
+}
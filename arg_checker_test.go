@@ -0,0 +1,95 @@
+package promptx
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCheckRules(t *testing.T) {
+	var scenarioTable = []struct {
+		tag      string
+		expected []string
+	}{
+		{tag: "NotEmpty", expected: []string{"NotEmpty"}},
+		{tag: "NotEmpty; Len(3,16)", expected: []string{"NotEmpty", "Len(3,16)"}},
+		{tag: "Regex(^(a;b)+$);Len(2,)", expected: []string{"Regex(^(a;b)+$)", "Len(2,)"}},
+		{tag: `Regex(a\;b);OneOf(x|y)`, expected: []string{`Regex(a\;b)`, "OneOf(x|y)"}},
+		{tag: ";;", expected: []string{}},
+	}
+	for _, s := range scenarioTable {
+		actual := splitCheckRules(s.tag)
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %#v, but got %#v", s.tag, s.expected, actual)
+		}
+	}
+}
+
+func TestCompileCheck(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/file.txt"
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var scenarioTable = []struct {
+		tag    string
+		value  string
+		failed string
+	}{
+		{tag: "Range(1,65535)", value: "8080"},
+		{tag: "Range(1,65535)", value: "0", failed: "Range(1,65535)"},
+		{tag: "Range(1,65535)", value: "abc", failed: "Range(1,65535)"},
+		{tag: "Range(,10)", value: "-100"},
+		{tag: "Len(3,16)", value: "用户名"},
+		{tag: "Len(3,16)", value: "ab", failed: "Len(3,16)"},
+		{tag: "Len(2)", value: "abc", failed: "Len(2)"},
+		{tag: "Regex(^[a-z]{2,4}$)", value: "abc"},
+		{tag: "Regex(^[a-z]{2,4}$)", value: "a1", failed: "Regex(^[a-z]{2,4}$)"},
+		{tag: "OneOf(tcp|udp)", value: "udp"},
+		{tag: "OneOf(tcp|udp)", value: "http", failed: "OneOf(tcp|udp)"},
+		{tag: "NotEmpty;Len(3,);Regex(^[a-z]+$)", value: "", failed: "NotEmpty"},
+		{tag: "NotEmpty;Len(3,);Regex(^[a-z]+$)", value: "ab", failed: "Len(3,)"},
+		{tag: "NotEmpty;Len(3,);Regex(^[a-z]+$)", value: "abC", failed: "Regex(^[a-z]+$)"},
+		{tag: "Email", value: "alice@example.com"},
+		{tag: "Email", value: "Alice <alice@example.com>", failed: "Email"},
+		{tag: "Hostname", value: "api.example.com"},
+		{tag: "Hostname", value: "-bad.example.com", failed: "Hostname"},
+		{tag: "URL", value: "https://example.com/path"},
+		{tag: "URL", value: "example.com", failed: "URL"},
+		{tag: "IP", value: "::1"},
+		{tag: "IP", value: "1.2.3", failed: "IP"},
+		{tag: "CIDR", value: "10.0.0.0/8"},
+		{tag: "CIDR", value: "10.0.0.0", failed: "CIDR"},
+		{tag: "FileExists", value: file},
+		{tag: "FileExists", value: dir, failed: "FileExists"},
+		{tag: "DirExists", value: dir},
+		{tag: "DirExists", value: file, failed: "DirExists"},
+	}
+	for _, s := range scenarioTable {
+		checker, err := compileCheck(s.tag)
+		if err != nil {
+			t.Errorf("%s: compile failed: %v", s.tag, err)
+			continue
+		}
+		err = checker(s.value)
+		if s.failed == "" {
+			if err != nil {
+				t.Errorf("%s(%q): should pass, but got %v", s.tag, s.value, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), s.failed+": ") {
+			t.Errorf("%s(%q): should fail with rule %s, but got %v", s.tag, s.value, s.failed, err)
+		}
+	}
+}
+
+func TestCompileCheckError(t *testing.T) {
+	for _, tag := range []string{"Unknown", "Unknown(1)", "Range(a,b)", "Range(1,2,3)", "Regex([a-z)"} {
+		if _, err := compileCheck(tag); err == nil {
+			t.Errorf("%s: should return error", tag)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package promptx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/completion"
+)
+
+// CompleteFunc 参数值补全函数
+// args 为当前参数之前已经确定的参数值（字段名 -> 值）, 多值参数（slice）包含已经输入的值, 多个值使用逗号连接.
+// input 为正在输入的参数值.
+// 返回的建议需要自行根据 input 过滤. 补全时 ctx 可能为 nil.
+type CompleteFunc func(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest
+
+var (
+	// completers 注册的参数值补全函数
+	completers = make(map[string]CompleteFunc)
+)
+
+// RegisterCompleter 注册参数值补全函数, 在 complete tag 中使用
+func RegisterCompleter(name string, fn CompleteFunc) {
+	completers[name] = fn
+}
+
+func init() {
+	// 注册内置补全函数
+	RegisterCompleter("file", CompleteFile)
+	RegisterCompleter("dir", CompleteDir)
+}
+
+// ArgComplete 为参数设置补全函数, 优先级高于 complete tag 以及选项
+// field: 参数结构体的字段名
+func (c *Command) ArgComplete(field string, fn CompleteFunc) *Command {
+	for _, def := range c.argDefs {
+		if def.Name == field {
+			def.CompleteFunc = fn
+			return c
+		}
+	}
+	panic("promptx: command " + c.name + " has no argument " + field)
+}
+
+// CompleteFile 补全文件路径（包含目录）
+func CompleteFile(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+	return completePath(input, false)
+}
+
+// CompleteDir 补全目录路径
+func CompleteDir(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+	return completePath(input, true)
+}
+
+// completePath 列出 input 所在目录中以 input 文件名开头的条目, 目录以 / 结尾
+func completePath(input string, onlyDir bool) []*completion.Suggest {
+	dir, base := filepath.Split(input)
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+	var suggests []*completion.Suggest
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		// 隐藏文件只有在输入 . 开头时提示
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(readDir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if onlyDir && !isDir {
+			continue
+		}
+		if isDir {
+			name += string(filepath.Separator)
+		}
+		suggests = append(suggests, &completion.Suggest{Text: dir + name})
+	}
+	return suggests
+}
+
+// argValueSuggest 查找参数值的建议
+// 依次使用参数的补全函数、选项、complete tag 指定的补全函数, 布尔类型提示 true/false.
+// prefix: 补全文本需要保留的前缀, input: 已经输入的参数值
+func argValueSuggest(ctx blocks.Context, def *ArgDef, args map[string]string, partial *token, prefix, input string) []*completion.Suggest {
+	// 多选参数使用逗号分隔多个值, 只补全最后一个值
+	if def.Multi {
+		if idx := strings.LastIndex(input, ","); idx >= 0 {
+			prefix, input = prefix+input[:idx+1], input[idx+1:]
+		}
+	}
+	var (
+		options []*completion.Suggest
+		// 补全函数自行过滤, 选项使用模糊匹配过滤
+		filter bool
+	)
+	switch {
+	case def.CompleteFunc != nil:
+		options = def.CompleteFunc(ctx, args, input)
+	case def.IsSelect:
+		options, filter = def.selectOptions(ctx, args), true
+	case def.Complete != "":
+		if fn, ok := completers[def.Complete]; ok {
+			options = fn(ctx, args, input)
+		}
+	case def.Type != nil && def.Type.Kind() == reflect.Bool:
+		options = []*completion.Suggest{{Text: "true"}, {Text: "false"}}
+		filter = true
+	}
+
+	var suggests []*completion.Suggest
+	for _, opt := range options {
+		if filter && !completion.FuzzyMatchRunes([]rune(opt.Text), []rune(input)) {
+			continue
+		}
+		desc := opt.Description
+		if desc == "" {
+			desc = def.Prompt
+		}
+		suggests = append(suggests, &completion.Suggest{
+			Text:        completeText(partial, prefix+opt.Text),
+			Description: desc,
+		})
+	}
+	return suggests
+}
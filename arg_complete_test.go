@@ -0,0 +1,77 @@
+package promptx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/completion"
+)
+
+type completeTestArgs struct {
+	Src    string `arg:"source file" complete:"file"`
+	Dst    string `arg:"target dir" complete:"dir"`
+	Force  bool   `arg:"force"`
+	Level  string `arg:"level" complete:"testLevels"`
+	Remark string `arg:"remark"`
+}
+
+func TestArgValueSuggest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.log", "app.conf", ".hidden", "my file.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "apps"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dir += string(filepath.Separator)
+
+	RegisterCompleter("testLevels", func(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+		var suggests []*completion.Suggest
+		for _, v := range []string{"debug", "info", "warn"} {
+			if strings.HasPrefix(v, input) {
+				suggests = append(suggests, &completion.Suggest{Text: v})
+			}
+		}
+		return suggests
+	})
+	cp := NewCommandWithFunc("cp", "copy file", func(ctx Context, arg *completeTestArgs) {})
+	cp.ArgComplete("Remark", func(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+		return []*completion.Suggest{{Text: args["Level"] + "-remark", Description: "remark for level"}}
+	})
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(cp)
+
+	var scenarioTable = []struct {
+		line     string
+		expected []string
+		desc     string
+	}{
+		{line: "cp " + dir + "app", expected: []string{dir + "app.conf", dir + "app.log", dir + "apps/"}, desc: "source file"},
+		{line: "cp " + dir + ".", expected: []string{dir + ".hidden"}, desc: "source file"},
+		{line: "cp " + dir + "my", expected: []string{"'" + dir + "my file.txt'"}, desc: "source file"},
+		{line: "cp a " + dir, expected: []string{dir + "apps/"}, desc: "target dir"},
+		{line: "cp a b ", expected: []string{"true", "false"}, desc: "force"},
+		{line: "cp a b f", expected: []string{"false"}, desc: "force"},
+		{line: "cp a b true i", expected: []string{"info"}, desc: "level"},
+		{line: "cp a b true info ", expected: []string{"info-remark"}, desc: "remark for level"},
+	}
+	for _, s := range scenarioTable {
+		var actual []string
+		for _, v := range root.FindSuggest(*buffer.NewDocumentWithCursor(s.line, len([]rune(s.line)))) {
+			actual = append(actual, v.Text)
+			if v.Description != s.desc {
+				t.Errorf("%q: description should be %q, but got %q", s.line, s.desc, v.Description)
+			}
+		}
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %#v, but got %#v", s.line, s.expected, actual)
+		}
+	}
+}
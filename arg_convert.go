@@ -0,0 +1,211 @@
+package promptx
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ConvertFunc 参数转换函数类型, 将命令行字符串转换为字段类型的值
+type ConvertFunc func(value string) (any, error)
+
+var (
+	// converters 注册的类型转换器
+	converters = make(map[reflect.Type]ConvertFunc)
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RegisterConverter 注册类型转换器
+// 字段类型为 typ 时, 使用 fn 将命令行字符串转换为字段值. fn 返回值的类型必须可以赋值给 typ
+func RegisterConverter(typ reflect.Type, fn ConvertFunc) {
+	converters[typ] = fn
+}
+
+// RegisterTypeConverter 注册类型转换器（泛型方式）
+func RegisterTypeConverter[T any](fn func(value string) (T, error)) {
+	RegisterConverter(reflect.TypeOf((*T)(nil)).Elem(), func(value string) (any, error) {
+		return fn(value)
+	})
+}
+
+// timeLayouts time.Time 支持的时间格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.DateTime,
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+	time.TimeOnly,
+}
+
+func init() {
+	// 注册内置转换器
+	RegisterTypeConverter(time.ParseDuration)
+	RegisterTypeConverter(ConvertTime)
+	RegisterTypeConverter(ConvertIP)
+}
+
+// ConvertTime 转换时间, 支持 RFC3339, "2006-01-02 15:04:05", "2006-01-02", "15:04:05" 等格式
+func ConvertTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// ConvertIP 转换 IP 地址
+func ConvertIP(value string) (net.IP, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", value)
+	}
+	return ip, nil
+}
+
+// customConvert 使用注册的转换器或者 encoding.TextUnmarshaler 转换字段值
+func customConvert(str string, fieldType reflect.Type) (value reflect.Value, ok bool, err error) {
+	if fn, find := converters[fieldType]; find {
+		v, err := fn(str)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() {
+			return reflect.Zero(fieldType), true, nil
+		}
+		if !rv.Type().AssignableTo(fieldType) {
+			return reflect.Value{}, true, fmt.Errorf("converter for %v returns type %v", fieldType, rv.Type())
+		}
+		return rv, true, nil
+	}
+	if reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		ptr := reflect.New(fieldType)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+			return reflect.Value{}, true, err
+		}
+		return ptr.Elem(), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+
+// isCustomType 是否使用注册的转换器或者 encoding.TextUnmarshaler 处理
+func isCustomType(fieldType reflect.Type) bool {
+	if _, ok := converters[fieldType]; ok {
+		return true
+	}
+	return reflect.PointerTo(fieldType).Implements(textUnmarshalerType)
+}
+
+// isMultiValueType 是否是可以接收多个值的类型（slice/map）
+func isMultiValueType(fieldType reflect.Type) bool {
+	if fieldType == nil || isCustomType(fieldType) {
+		return false
+	}
+	switch fieldType.Kind() {
+	case reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// convertSlice 转换 slice, 多个值之间使用逗号分隔
+func convertSlice(str string, fieldType reflect.Type) (reflect.Value, error) {
+	slice := reflect.MakeSlice(fieldType, 0, 0)
+	if str == "" {
+		return slice, nil
+	}
+	for _, item := range strings.Split(str, ",") {
+		elem, err := getArgValueFromString(item, fieldType.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	return slice, nil
+}
+
+// convertMap 转换 map, 格式为 k1=v1,k2=v2
+func convertMap(str string, fieldType reflect.Type) (reflect.Value, error) {
+	m := reflect.MakeMap(fieldType)
+	if str == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(str, ",") {
+		if err := setMapPair(m, pair); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return m, nil
+}
+
+// setMapPair 转换 key=value 并设置到 map 中
+func setMapPair(m reflect.Value, pair string) error {
+	k, v, ok := strings.Cut(pair, "=")
+	if !ok {
+		return fmt.Errorf("invalid key=value pair %q", pair)
+	}
+	key, err := getArgValueFromString(k, m.Type().Key())
+	if err != nil {
+		return err
+	}
+	value, err := getArgValueFromString(v, m.Type().Elem())
+	if err != nil {
+		return err
+	}
+	m.SetMapIndex(key, value)
+	return nil
+}
+
+// convertElement 转换 slice/map 的一个元素, 值中的逗号不作为分隔符
+func convertElement(str string, fieldType reflect.Type) (reflect.Value, error) {
+	if fieldType.Kind() == reflect.Map {
+		m := reflect.MakeMap(fieldType)
+		return m, setMapPair(m, str)
+	}
+	elem, err := getArgValueFromString(str, fieldType.Elem())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.Append(reflect.MakeSlice(fieldType, 0, 1), elem), nil
+}
+
+// getArgValuesFromStrings 从多个字符串获取参数值
+// slice 类型会合并所有值, map 类型会合并所有键值对, 其他类型使用最后一个值.
+// split 为 false 时每个字符串都是一个元素, 不使用逗号分隔
+func getArgValuesFromStrings(strs []string, fieldType reflect.Type, split bool) (reflect.Value, error) {
+	if len(strs) == 0 {
+		return reflect.Zero(fieldType), nil
+	}
+	if !isMultiValueType(fieldType) {
+		return getArgValueFromString(strs[len(strs)-1], fieldType)
+	}
+	var result reflect.Value
+	convert := getArgValueFromString
+	if !split {
+		convert = convertElement
+	}
+	for _, str := range strs {
+		value, err := convert(str, fieldType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		switch {
+		case !result.IsValid():
+			result = value
+		case fieldType.Kind() == reflect.Slice:
+			result = reflect.AppendSlice(result, value)
+		default:
+			iter := value.MapRange()
+			for iter.Next() {
+				result.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package promptx
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type convertLevel string
+
+type convertPoint struct {
+	X, Y int
+}
+
+func (p *convertPoint) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d:%d", &p.X, &p.Y)
+	return err
+}
+
+type convertServer struct {
+	Name string
+}
+
+type convertTestArgs struct {
+	Level   convertLevel      `arg:"level"`
+	Timeout time.Duration     `arg:"timeout"`
+	At      time.Time         `arg:"at"`
+	Addr    net.IP            `arg:"addr"`
+	Point   convertPoint      `arg:"point"`
+	Server  convertServer     `arg:"server"`
+	Labels  map[string]string `arg:"labels" flag:"label,l"`
+	Ports   []int             `arg:"ports" flag:"port,p"`
+	Files   []string          `arg:"files"`
+}
+
+func TestConvertArgValue(t *testing.T) {
+	RegisterTypeConverter(func(value string) (convertServer, error) {
+		if value == "" {
+			return convertServer{}, fmt.Errorf("empty server")
+		}
+		return convertServer{Name: strings.ToUpper(value)}, nil
+	})
+
+	defs := parseArgDefs(&convertTestArgs{})
+	args := []string{
+		"debug", "1m30s", "2024-05-01 10:20:30", "127.0.0.1", "3:4", "dev",
+		"-l", "a=1,b=2", "--label=c=3", "-p", "80", "--port", "443,8080",
+		"x.txt", "y z.txt",
+	}
+	values, err := checkArgs(nil, defs, args)
+	if err != nil {
+		t.Fatalf("check args failed: %v", err)
+	}
+	arg := createArgValueFromStrings(defs, reflect.TypeOf(convertTestArgs{}), values).Interface().(convertTestArgs)
+
+	expected := convertTestArgs{
+		Level:   "debug",
+		Timeout: 90 * time.Second,
+		At:      time.Date(2024, 5, 1, 10, 20, 30, 0, time.Local),
+		Addr:    net.ParseIP("127.0.0.1"),
+		Point:   convertPoint{X: 3, Y: 4},
+		Server:  convertServer{Name: "DEV"},
+		Labels:  map[string]string{"a": "1", "b": "2", "c": "3"},
+		Ports:   []int{80, 443, 8080},
+		Files:   []string{"x.txt", "y z.txt"},
+	}
+	if !reflect.DeepEqual(arg, expected) {
+		t.Errorf("should be %+v, but got %+v", expected, arg)
+	}
+}
+
+func TestConvertArgValueError(t *testing.T) {
+	defs := parseArgDefs(&convertTestArgs{})
+	var scenarioTable = [][]string{
+		{"debug", "10x"},
+		{"debug", "1s", "yesterday"},
+		{"debug", "1s", "2024-05-01", "256.0.0.1"},
+		{"debug", "1s", "2024-05-01", "::1", "3"},
+		{"debug", "1s", "2024-05-01", "::1", "3:4", "dev", "-l", "novalue"},
+		{"debug", "1s", "2024-05-01", "::1", "3:4", "dev", "-p", "http"},
+	}
+	for _, args := range scenarioTable {
+		if _, err := checkArgs(nil, defs, args); err == nil {
+			t.Errorf("%v: should be failed", args)
+		}
+	}
+}
+
+type convertCommaArgs struct {
+	Names  []string          `arg:"names"`
+	Labels map[string]string `arg:"labels" flag:"label,l"`
+	Tags   []string          `arg:"tags" flag:"tag" default:"a,b"`
+}
+
+func TestConvertArgValueKeepComma(t *testing.T) {
+	defs := parseArgDefs(&convertCommaArgs{})
+	// 引号中的逗号属于参数值, 命名参数以及默认值使用逗号分隔
+	args, err := splitCommandLine(`"hello, world" x,y -l k=1,v=2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := checkArgs(nil, defs, args)
+	if err != nil {
+		t.Fatalf("check args failed: %v", err)
+	}
+	arg := createArgValueFromStrings(defs, reflect.TypeOf(convertCommaArgs{}), values).Interface().(convertCommaArgs)
+	expected := convertCommaArgs{
+		Names:  []string{"hello, world", "x,y"},
+		Labels: map[string]string{"k": "1", "v": "2"},
+		Tags:   []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(arg, expected) {
+		t.Errorf("should be %+v, but got %+v", expected, arg)
+	}
+}
@@ -0,0 +1,130 @@
+package promptx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// flagTerminator 结束命名参数解析的标记, 之后的参数全部作为位置参数
+const flagTerminator = "--"
+
+// IsFlag 是否为命名参数
+func (def *ArgDef) IsFlag() bool {
+	return def.Flag != "" || def.Short != ""
+}
+
+// IsBoolFlag 是否为布尔开关类型的命名参数
+func (def *ArgDef) IsBoolFlag() bool {
+	return def.IsFlag() && def.Type != nil && def.Type.Kind() == reflect.Bool
+}
+
+// FlagName 返回命名参数在命令行中的显示名称
+func (def *ArgDef) FlagName() string {
+	if def.Flag != "" {
+		return "--" + def.Flag
+	}
+	return "-" + def.Short
+}
+
+// parseFlagTag 解析 flag tag
+// 格式: flag:"name,n" name 为长名称, n 为短名称
+func parseFlagTag(tag string, def *ArgDef) {
+	parts := strings.Split(tag, ",")
+	def.Flag = strings.TrimLeft(strings.TrimSpace(parts[0]), "-")
+	if len(parts) > 1 {
+		def.Short = strings.TrimLeft(strings.TrimSpace(parts[1]), "-")
+	}
+	// 命名参数默认可选
+	if def.IsFlag() {
+		def.Required = false
+	}
+}
+
+// findFlag 根据名称查找命名参数
+func findFlag(defs []*ArgDef, name string, short bool) *ArgDef {
+	for _, def := range defs {
+		if !def.IsFlag() {
+			continue
+		}
+		if short && def.Short != "" && def.Short == name {
+			return def
+		}
+		if !short && def.Flag != "" && def.Flag == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// hasFlags 参数定义中是否包含命名参数
+func hasFlags(defs []*ArgDef) bool {
+	for _, def := range defs {
+		if def.IsFlag() {
+			return true
+		}
+	}
+	return false
+}
+
+// isFlagArg 检查参数是否是命名参数的形式
+func isFlagArg(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || arg == flagTerminator {
+		return false
+	}
+	// 负数作为位置参数处理
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return false
+	}
+	return true
+}
+
+// splitFlagArgs 从命令行参数中分离出命名参数和位置参数
+// 支持 --name=value, --name value, -n value, -n=value, 布尔开关 --name/-n, 以及 -- 结束命名参数
+// 同一个命名参数可以重复指定, 所有值按顺序保存
+func splitFlagArgs(defs []*ArgDef, args []string) (flags map[*ArgDef][]string, positional []string, err error) {
+	flags = make(map[*ArgDef][]string)
+	if !hasFlags(defs) {
+		return flags, args, nil
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == flagTerminator {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !isFlagArg(arg) {
+			positional = append(positional, arg)
+			continue
+		}
+		short := !strings.HasPrefix(arg, "--")
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+		def := findFlag(defs, name, short)
+		if def == nil {
+			return nil, nil, fmt.Errorf("unknown flag %s", arg)
+		}
+		if !hasValue {
+			if def.IsBoolFlag() {
+				value = "true"
+			} else if i+1 < len(args) {
+				i++
+				value = args[i]
+			} else {
+				return nil, nil, fmt.Errorf("flag %s needs a value", def.FlagName())
+			}
+		}
+		if def.IsBoolFlag() {
+			if _, perr := strconv.ParseBool(value); perr != nil {
+				return nil, nil, fmt.Errorf("invalid value %q for flag %s", value, def.FlagName())
+			}
+		}
+		// 重复指定时, slice/map 类型合并所有值, 其他类型使用最后一个值
+		flags[def] = append(flags[def], value)
+	}
+	return flags, positional, nil
+}
@@ -0,0 +1,103 @@
+package promptx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/buffer"
+)
+
+type flagTestArgs struct {
+	Server  string `arg:"server"`
+	Account string `arg:"account,optional"`
+	Port    int    `arg:"port" flag:"port,p"`
+	Verbose bool   `arg:"verbose" flag:"verbose,v"`
+	Name    string `arg:"name" flag:",n"`
+}
+
+func TestParseFlagTag(t *testing.T) {
+	defs := parseArgDefs(&flagTestArgs{})
+	if len(defs) != 5 {
+		t.Fatalf("should parse 5 defs, but got %d", len(defs))
+	}
+	port := defs[2]
+	if port.Flag != "port" || port.Short != "p" || port.Required {
+		t.Errorf("unexpected port def %+v", port)
+	}
+	if !defs[3].IsBoolFlag() || defs[2].IsBoolFlag() {
+		t.Errorf("only verbose should be bool flag")
+	}
+	if defs[4].Flag != "" || defs[4].Short != "n" || defs[4].FlagName() != "-n" {
+		t.Errorf("unexpected name def %+v", defs[4])
+	}
+}
+
+func TestCheckArgsWithFlags(t *testing.T) {
+	defs := parseArgDefs(&flagTestArgs{})
+	var scenarioTable = []struct {
+		args     []string
+		expected [][]string
+		err      bool
+	}{
+		{
+			args:     []string{"dev", "alice"},
+			expected: [][]string{{"dev"}, {"alice"}, nil, nil, nil},
+		},
+		{
+			args:     []string{"--port=8080", "dev", "-v", "alice", "-n", "bob"},
+			expected: [][]string{{"dev"}, {"alice"}, {"8080"}, {"true"}, {"bob"}},
+		},
+		{
+			args:     []string{"dev", "--port", "-1", "--verbose=false"},
+			expected: [][]string{{"dev"}, nil, {"-1"}, {"false"}, nil},
+		},
+		{
+			args:     []string{"-p", "1", "--", "-v", "--port"},
+			expected: [][]string{{"-v"}, {"--port"}, {"1"}, nil, nil},
+		},
+		{args: []string{"dev", "--unknown"}, err: true},
+		{args: []string{"dev", "--port"}, err: true},
+		{args: []string{"dev", "--verbose=maybe"}, err: true},
+	}
+	for _, s := range scenarioTable {
+		actual, err := checkArgs(nil, defs, s.args)
+		if (err != nil) != s.err {
+			t.Errorf("%v: unexpected error %v", s.args, err)
+			continue
+		}
+		if !s.err && !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%v: should be %#v, but got %#v", s.args, s.expected, actual)
+		}
+	}
+
+	value := createArgValueFromStrings(defs, reflect.TypeOf(flagTestArgs{}), [][]string{{"dev"}, nil, {"8080"}, {"true"}, nil})
+	arg := value.Interface().(flagTestArgs)
+	if arg.Port != 8080 || !arg.Verbose || arg.Server != "dev" {
+		t.Errorf("unexpected bind result %+v", arg)
+	}
+}
+
+func TestFindFlagSuggest(t *testing.T) {
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(NewCommandWithFunc("connect", "connect server", func(ctx Context, arg *flagTestArgs) {}))
+
+	var scenarioTable = []struct {
+		line     string
+		expected []string
+	}{
+		{line: "connect --", expected: []string{"--port", "--verbose"}},
+		{line: "connect -", expected: []string{"--port", "-p", "--verbose", "-v", "-n"}},
+		{line: "connect dev -v --", expected: []string{"--port"}},
+		{line: "connect --port -", expected: nil},
+		{line: "connect -- -", expected: nil},
+	}
+	for _, s := range scenarioTable {
+		var actual []string
+		for _, v := range root.FindSuggest(*buffer.NewDocumentWithCursor(s.line, len([]rune(s.line)))) {
+			actual = append(actual, v.Text)
+		}
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %#v, but got %#v", s.line, s.expected, actual)
+		}
+	}
+}
@@ -3,13 +3,18 @@ package promptx
 import (
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
 
 	"github.com/aggronmagi/promptx/v2/blocks"
 	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/completion"
 )
 
+// errUserCancel 用户取消输入
+var errUserCancel = errors.New("user cancel")
+
 // ArgDef 参数定义
 type ArgDef struct {
 	// 字段索引
@@ -20,7 +25,7 @@ type ArgDef struct {
 	Prompt string
 	// 字段类型
 	Type reflect.Type
-	// Check 方法名（从 tag 获取）
+	// Check 规则（从 tag 获取），多个规则使用 ; 分隔，例如 NotEmpty;Len(3,16)
 	CheckName string
 	// 是否为必填项
 	Required bool
@@ -28,6 +33,28 @@ type ArgDef struct {
 	IsSelect bool
 	// 选择选项列表（如果是 Select 类型）
 	SelectOptions []string
+	// 是否为多选（从 multi tag 获取），用于 slice 类型
+	Multi bool
+	// 多选时最少选择的数量
+	MinSelect int
+	// 多选时最多选择的数量, 0 表示不限制
+	MaxSelect int
+	// 动态选项函数名称（从 selectfn tag 获取），通过 RegisterSelectFunc 注册
+	SelectFuncName string
+	// 动态选项函数（通过 Command.ArgSelect 设置）
+	SelectFunc SelectFunc
+	// 补全函数名称（从 complete tag 获取），内置 file/dir, 通过 RegisterCompleter 注册
+	Complete string
+	// 补全函数（通过 Command.ArgComplete 设置）
+	CompleteFunc CompleteFunc
+	// 命名参数长名称（从 flag tag 获取），使用 --name 指定
+	Flag string
+	// 命名参数短名称（从 flag tag 获取），使用 -n 指定
+	Short string
+	// 默认值（从 default tag 获取）
+	Default string
+	// 环境变量名称（从 env tag 获取）
+	Env string
 }
 
 // parseArgDefs 解析参数定义
@@ -80,6 +107,12 @@ func parseArgTag(field reflect.StructField, def *ArgDef) (ignore bool) {
 		ignore = true
 		return
 	}
+	// 解析 flag tag（命名参数）
+	flagTag := field.Tag.Get("flag")
+	if flagTag != "" {
+		parseFlagTag(flagTag, def)
+	}
+
 	// 解析 arg tag
 	argTag := field.Tag.Get("arg")
 	if argTag != "" {
@@ -87,10 +120,13 @@ func parseArgTag(field reflect.StructField, def *ArgDef) (ignore bool) {
 		if len(parts) > 0 && parts[0] != "" {
 			def.Prompt = parts[0]
 		}
-		// 检查是否有 required=false
+		// 检查是否有 optional/required
 		for _, part := range parts[1:] {
-			if part == "optional" {
+			switch part {
+			case "optional":
 				def.Required = false
+			case "required":
+				def.Required = true
 			}
 		}
 	}
@@ -107,6 +143,17 @@ func parseArgTag(field reflect.StructField, def *ArgDef) (ignore bool) {
 		def.CheckName = checkTag
 	}
 
+	// 解析 default tag（默认值）
+	if defaultTag, ok := field.Tag.Lookup("default"); ok {
+		def.Default = defaultTag
+	}
+
+	// 解析 env tag（环境变量）
+	envTag := field.Tag.Get("env")
+	if envTag != "" {
+		def.Env = envTag
+	}
+
 	// 解析 select tag（选择类型）
 	selectTag := field.Tag.Get("select")
 	if selectTag != "" {
@@ -116,6 +163,25 @@ func parseArgTag(field reflect.StructField, def *ArgDef) (ignore bool) {
 		def.SelectOptions = options
 	}
 
+	// 解析 multi tag（多选）
+	multiTag := field.Tag.Get("multi")
+	if multiTag != "" {
+		parseMultiTag(multiTag, def)
+	}
+
+	// 解析 selectfn tag（动态选择类型）
+	selectFnTag := field.Tag.Get("selectfn")
+	if selectFnTag != "" {
+		def.IsSelect = true
+		def.SelectFuncName = selectFnTag
+	}
+
+	// 解析 complete tag（补全函数）
+	completeTag := field.Tag.Get("complete")
+	if completeTag != "" {
+		def.Complete = completeTag
+	}
+
 	// 如果没有设置 prompt，使用字段名
 	if def.Prompt == "" {
 		def.Prompt = field.Name
@@ -158,25 +224,18 @@ func createArgValue(defs []*ArgDef, argType reflect.Type) reflect.Value {
 
 // setDefaultValue 设置默认值
 func setDefaultValue(field reflect.Value, def *ArgDef) {
-	switch field.Kind() {
-	case reflect.String:
-		field.SetString("")
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		field.SetInt(0)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		field.SetUint(0)
-	case reflect.Float32, reflect.Float64:
-		field.SetFloat(0)
-	case reflect.Bool:
-		field.SetBool(false)
-	}
+	field.Set(reflect.Zero(field.Type()))
 }
 
 // getArgValueFromString 从字符串获取参数值
 func getArgValueFromString(str string, fieldType reflect.Type) (reflect.Value, error) {
+	// 注册的转换器以及实现 encoding.TextUnmarshaler 的类型
+	if value, ok, err := customConvert(str, fieldType); ok {
+		return value, err
+	}
 	switch fieldType.Kind() {
 	case reflect.String:
-		return reflect.ValueOf(str), nil
+		return reflect.ValueOf(str).Convert(fieldType), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		var v int64
 		_, err := fmt.Sscanf(str, "%d", &v)
@@ -200,20 +259,39 @@ func getArgValueFromString(str string, fieldType reflect.Type) (reflect.Value, e
 		return reflect.ValueOf(v).Convert(fieldType), nil
 	case reflect.Bool:
 		v := strings.ToLower(str) == "true" || str == "1"
-		return reflect.ValueOf(v), nil
+		return reflect.ValueOf(v).Convert(fieldType), nil
+	case reflect.Slice:
+		return convertSlice(str, fieldType)
+	case reflect.Map:
+		return convertMap(str, fieldType)
+	case reflect.Ptr:
+		elem, err := getArgValueFromString(str, fieldType.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(fieldType.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
 	default:
 		return reflect.Value{}, fmt.Errorf("unsupported type: %v", fieldType)
 	}
 }
 
 // createArgValueFromStrings 从字符串数组创建参数值
-func createArgValueFromStrings(defs []*ArgDef, argType reflect.Type, values []string) reflect.Value {
+// values 与 defs 一一对应, 每个参数可以有多个值（slice/map 类型）
+func createArgValueFromStrings(defs []*ArgDef, argType reflect.Type, values [][]string) reflect.Value {
 	if argType.Kind() == reflect.Ptr {
 		argType = argType.Elem()
 	}
 
 	value := reflect.New(argType).Elem()
+	fillArgValue(value, defs, values)
+
+	return value
+}
 
+// fillArgValue 使用字符串值填充结构体字段
+func fillArgValue(value reflect.Value, defs []*ArgDef, values [][]string) {
 	for i, def := range defs {
 		if i >= len(values) {
 			break
@@ -222,9 +300,9 @@ func createArgValueFromStrings(defs []*ArgDef, argType reflect.Type, values []st
 		if def.Index < value.NumField() {
 			field := value.Field(def.Index)
 			if field.CanSet() {
-				strValue := values[i]
-				if strValue != "" {
-					fieldValue, err := getArgValueFromString(strValue, def.Type)
+				strValues := values[i]
+				if len(strValues) > 0 {
+					fieldValue, err := getArgValuesFromStrings(strValues, def.Type, def.splitComma())
 					if err == nil {
 						field.Set(fieldValue)
 					}
@@ -235,78 +313,211 @@ func createArgValueFromStrings(defs []*ArgDef, argType reflect.Type, values []st
 			}
 		}
 	}
+}
 
-	return value
+// splitComma slice/map 类型的值是否使用逗号分隔多个元素.
+// 多选参数以及命名参数使用逗号分隔, 位置参数的每个值都是一个元素, 保留引号中的逗号
+func (def *ArgDef) splitComma() bool {
+	return def.Multi || def.IsFlag()
+}
+
+// tagValues 环境变量以及默认值使用逗号分隔多个元素
+func (def *ArgDef) tagValues(value string) []string {
+	if isMultiValueType(def.Type) && !def.splitComma() {
+		return strings.Split(value, ",")
+	}
+	return []string{value}
+}
+
+// validate 检查参数值是否合法（类型转换以及检查器）
+func (def *ArgDef) validate(value string) error {
+	if _, err := getArgValuesFromStrings([]string{value}, def.Type, def.splitComma()); err != nil {
+		return err
+	}
+	if def.CheckName != "" {
+		checker, err := compileCheck(def.CheckName)
+		if err != nil {
+			return err
+		}
+		if err := checker(value); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // checkArg 检查参数
-func checkArg(ctx blocks.Context, def *ArgDef, value string, args []string, index int) (string, error) {
+// values: 命令行中的参数值, 为空表示命令行未提供该参数
+// args: 之前已经确定的参数值, 传递给动态选项函数
+func checkArg(ctx blocks.Context, def *ArgDef, values []string, args map[string]string) ([]string, error) {
 	// 如果已经有值，先检查
-	if index < len(args) && args[index] != "" {
-		value = args[index]
-		// 执行检查
-		if def.CheckName != "" {
-			checker, ok := checkers[def.CheckName]
-			if ok {
-				if err := checker(value); err != nil {
-					return "", fmt.Errorf("invalid value for %s: %v", def.Prompt, err)
+	if len(values) > 0 && (len(values) > 1 || values[0] != "") {
+		for _, value := range values {
+			// 执行检查
+			if err := def.validate(value); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %v", def.Prompt, err)
+			}
+		}
+		if def.Multi {
+			if err := def.checkSelectCount(len(splitMultiValues(values))); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %v", def.Prompt, err)
+			}
+		}
+		return values, nil
+	}
+
+	// 命令行未提供, 优先使用环境变量
+	if def.Env != "" {
+		if value := os.Getenv(def.Env); value != "" {
+			values := def.tagValues(value)
+			for _, v := range values {
+				if err := def.validate(v); err != nil {
+					return nil, fmt.Errorf("invalid value for %s from env %s: %v", def.Prompt, def.Env, err)
 				}
 			}
+			return values, nil
+		}
+	}
+
+	// 可选参数以及布尔开关直接使用默认值. 必填参数没有终端无法交互输入时也使用默认值
+	if def.Default != "" && (!def.Required || def.IsBoolFlag() || checkTerminal(ctx) != nil) {
+		values := def.tagValues(def.Default)
+		for _, v := range values {
+			if err := def.validate(v); err != nil {
+				return nil, fmt.Errorf("invalid default value for %s: %v", def.Prompt, err)
+			}
 		}
-		return value, nil
+		return values, nil
 	}
 
-	// 如果没有值且是必填项，需要交互输入
+	// 布尔开关未指定时为 false, 不需要交互输入
+	if def.IsBoolFlag() {
+		return nil, nil
+	}
+
+	// 如果没有值且是必填项，需要交互输入. 默认值作为输入框的预设值
 	if def.Required {
-		if def.IsSelect && len(def.SelectOptions) > 0 {
+		// 脚本模式下没有终端时无法交互输入
+		if err := checkTerminal(ctx); err != nil {
+			return nil, fmt.Errorf("missing value for %s: %w", def.Prompt, err)
+		}
+		var options []*completion.Suggest
+		if def.IsSelect {
+			options = def.selectOptions(ctx, args)
+		}
+		if len(options) > 0 && def.Multi {
+			// 使用多选
+			return def.multiSelect(ctx, options)
+		} else if len(options) > 0 {
 			// 使用 Select
-			sel := ctx.RawSelect(def.Prompt, def.SelectOptions)
+			list := make([]string, 0, len(options))
+			opts := []blocks.SelectOption{blocks.WithSelectOptionOptions(options...)}
+			for k, v := range options {
+				list = append(list, v.Text)
+				if v.Text == def.Default {
+					opts = append(opts, blocks.WithSelectOptionDefaults(k))
+				}
+			}
+			sel := ctx.RawSelect(def.Prompt, list, opts...)
 			if sel < 0 {
-				return "", errors.New("user cancel")
+				return nil, errUserCancel
 			}
-			return def.SelectOptions[sel], nil
+			return []string{list[sel]}, nil
 		} else {
 			// 使用 Input
 			opts := []blocks.InputOption{}
-			// 如果有检查器，设置验证函数
-			if def.CheckName != "" {
-				checker, ok := checkers[def.CheckName]
-				if ok {
-					opts = append(opts, blocks.WithInputOptionValid(func(doc *buffer.Document) error {
-						return checker(doc.Text)
-					}))
+			multi := isMultiValueType(def.Type)
+			// 设置验证函数
+			opts = append(opts, blocks.WithInputOptionValid(func(doc *buffer.Document) error {
+				if !multi {
+					return def.validate(doc.Text)
+				}
+				// 多个值使用命令行分词规则输入
+				items, err := splitCommandLine(doc.Text)
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					if err := def.validate(item); err != nil {
+						return err
+					}
 				}
+				return nil
+			}))
+			if def.Default != "" {
+				opts = append(opts, blocks.WithInputOptionDefault(def.Default))
 			}
 			result, err := ctx.RawInput(def.Prompt, opts...)
 			if err != nil {
-				return "", err
+				return nil, err
+			}
+			if multi {
+				return splitCommandLine(result)
 			}
-			return result, nil
+			return []string{result}, nil
 		}
 	}
 
 	// 可选参数，返回空值
-	return "", nil
+	return nil, nil
 }
 
 // checkArgs 检查所有参数
-func checkArgs(ctx blocks.Context, defs []*ArgDef, args []string) ([]string, error) {
-	checkedArgs := make([]string, len(defs))
+// 命名参数从 flag 中获取, 其余参数按照字段顺序依次绑定位置参数.
+// 最后一个位置参数如果是 slice/map 类型, 会接收剩余的所有位置参数
+func checkArgs(ctx blocks.Context, defs []*ArgDef, args []string) ([][]string, error) {
+	flags, positional, err := splitFlagArgs(defs, args)
+	if err != nil {
+		return nil, err
+	}
 
+	checkedArgs := bindArgs(defs, flags, positional)
 	for i, def := range defs {
-		value, err := checkArg(ctx, def, "", args, i)
+		values, err := checkArg(ctx, def, checkedArgs[i], argValuesMap(defs[:i], checkedArgs[:i]))
 		if err != nil {
 			return nil, err
 		}
-		checkedArgs[i] = value
+		checkedArgs[i] = values
 	}
 
 	return checkedArgs, nil
 }
 
+// bindArgs 将命名参数和位置参数绑定到参数定义, 返回值与 defs 一一对应
+func bindArgs(defs []*ArgDef, flags map[*ArgDef][]string, positional []string) [][]string {
+	last := lastPositional(defs)
+	values := make([][]string, len(defs))
+	index := 0
+	for i, def := range defs {
+		switch {
+		case def.IsFlag():
+			values[i] = flags[def]
+		case index >= len(positional):
+		case i == last && isMultiValueType(def.Type):
+			values[i] = positional[index:]
+			index = len(positional)
+		default:
+			values[i] = positional[index : index+1]
+			index++
+		}
+	}
+	return values
+}
+
+// lastPositional 返回最后一个位置参数的下标, 没有位置参数返回 -1
+func lastPositional(defs []*ArgDef) int {
+	last := -1
+	for i, def := range defs {
+		if !def.IsFlag() {
+			last = i
+		}
+	}
+	return last
+}
+
 // createArgValueForCommander 为 Commander 类型创建参数值
 // originalType 是原始类型（可能是指针或值）
-func createArgValueForCommander(defs []*ArgDef, originalType reflect.Type, values []string) reflect.Value {
+func createArgValueForCommander(defs []*ArgDef, originalType reflect.Type, values [][]string) reflect.Value {
 	// 确定结构体类型
 	structType := originalType
 	if structType.Kind() == reflect.Ptr {
@@ -317,26 +528,7 @@ func createArgValueForCommander(defs []*ArgDef, originalType reflect.Type, value
 	value := reflect.New(structType).Elem()
 
 	// 填充字段
-	for i, def := range defs {
-		if i >= len(values) {
-			break
-		}
-
-		if def.Index < value.NumField() {
-			field := value.Field(def.Index)
-			if field.CanSet() {
-				strValue := values[i]
-				if strValue != "" {
-					fieldValue, err := getArgValueFromString(strValue, def.Type)
-					if err == nil {
-						field.Set(fieldValue)
-					}
-				} else {
-					setDefaultValue(field, def)
-				}
-			}
-		}
-	}
+	fillArgValue(value, defs, values)
 
 	// 根据原始类型返回值或指针
 	if originalType.Kind() == reflect.Ptr {
@@ -0,0 +1,91 @@
+package promptx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// noTerminalContext 没有终端, 无法交互输入
+type noTerminalContext struct {
+	blocks.Context
+}
+
+func (noTerminalContext) checkTerminal() error { return ErrNoTerminal }
+
+type defaultTestArgs struct {
+	Server  string   `arg:"server" env:"PROMPTX_TEST_SERVER"`
+	Account string   `arg:"account,optional" default:"guest"`
+	Port    int      `arg:"port" flag:"port,p" env:"PROMPTX_TEST_PORT" default:"8080"`
+	Verbose bool     `arg:"verbose" flag:"verbose,v" default:"true"`
+	Tags    []string `arg:"tags" flag:"tag" default:"a,b"`
+}
+
+func TestArgDefaultAndEnv(t *testing.T) {
+	defs := parseArgDefs(&defaultTestArgs{})
+	if defs[1].Default != "guest" || defs[2].Env != "PROMPTX_TEST_PORT" {
+		t.Fatalf("unexpected defs %+v %+v", defs[1], defs[2])
+	}
+
+	t.Setenv("PROMPTX_TEST_SERVER", "dev")
+	t.Setenv("PROMPTX_TEST_PORT", "")
+
+	var scenarioTable = []struct {
+		args     []string
+		env      string
+		expected defaultTestArgs
+	}{
+		{
+			expected: defaultTestArgs{Server: "dev", Account: "guest", Port: 8080, Verbose: true, Tags: []string{"a", "b"}},
+		},
+		{
+			args:     []string{"test", "alice", "--verbose=false", "--tag", "c"},
+			env:      "9000",
+			expected: defaultTestArgs{Server: "test", Account: "alice", Port: 9000, Verbose: false, Tags: []string{"c"}},
+		},
+		{
+			args:     []string{"-p", "1"},
+			env:      "9000",
+			expected: defaultTestArgs{Server: "dev", Account: "guest", Port: 1, Verbose: true, Tags: []string{"a", "b"}},
+		},
+	}
+	for _, s := range scenarioTable {
+		t.Setenv("PROMPTX_TEST_PORT", s.env)
+		values, err := checkArgs(nil, defs, s.args)
+		if err != nil {
+			t.Errorf("%v: check args failed: %v", s.args, err)
+			continue
+		}
+		actual := createArgValueFromStrings(defs, reflect.TypeOf(defaultTestArgs{}), values).Interface()
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%v: should be %+v, but got %+v", s.args, s.expected, actual)
+		}
+	}
+
+	t.Setenv("PROMPTX_TEST_PORT", "http")
+	if _, err := checkArgs(nil, defs, nil); err == nil {
+		t.Errorf("invalid env value should be failed")
+	}
+}
+
+type requiredDefaultArgs struct {
+	Port int    `arg:"port" default:"8080"`
+	Name string `arg:"name"`
+}
+
+func TestArgRequiredDefaultWithoutTerminal(t *testing.T) {
+	defs := parseArgDefs(&requiredDefaultArgs{})
+	// 没有终端时必填参数使用默认值
+	values, err := checkArgs(noTerminalContext{}, defs[:1], nil)
+	if err != nil {
+		t.Fatalf("check args failed: %v", err)
+	}
+	if !reflect.DeepEqual(values, [][]string{{"8080"}}) {
+		t.Errorf("port should use default value, but got %v", values)
+	}
+	// 没有默认值的必填参数仍然报错
+	if _, err = checkArgs(noTerminalContext{}, defs, nil); err == nil {
+		t.Errorf("missing required argument without default should be failed")
+	}
+}
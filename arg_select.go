@@ -0,0 +1,138 @@
+package promptx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/completion"
+)
+
+// SelectFunc 动态选项函数, 在运行时提供参数的可选值
+// args 为当前参数之前已经确定的参数值（字段名 -> 值）, 可以根据前面的参数返回不同的选项.
+// 补全时 ctx 可能为 nil.
+type SelectFunc func(ctx blocks.Context, args map[string]string) []*completion.Suggest
+
+var (
+	// selectFuncs 注册的动态选项函数
+	selectFuncs = make(map[string]SelectFunc)
+)
+
+// RegisterSelectFunc 注册动态选项函数, 在 selectfn tag 中使用
+func RegisterSelectFunc(name string, fn SelectFunc) {
+	selectFuncs[name] = fn
+}
+
+// ArgSelect 为参数设置动态选项函数, 优先级高于 select/selectfn tag
+// field: 参数结构体的字段名
+func (c *Command) ArgSelect(field string, fn SelectFunc) *Command {
+	for _, def := range c.argDefs {
+		if def.Name == field {
+			def.IsSelect = true
+			def.SelectFunc = fn
+			return c
+		}
+	}
+	panic("promptx: command " + c.name + " has no argument " + field)
+}
+
+// selectOptions 获取参数的可选值
+func (def *ArgDef) selectOptions(ctx blocks.Context, args map[string]string) []*completion.Suggest {
+	if def.SelectFunc != nil {
+		return def.SelectFunc(ctx, args)
+	}
+	if def.SelectFuncName != "" {
+		if fn, ok := selectFuncs[def.SelectFuncName]; ok {
+			return fn(ctx, args)
+		}
+		return nil
+	}
+	suggests := make([]*completion.Suggest, 0, len(def.SelectOptions))
+	for _, v := range def.SelectOptions {
+		suggests = append(suggests, &completion.Suggest{Text: v})
+	}
+	return suggests
+}
+
+// argValuesMap 将已经确定的参数值转换为 SelectFunc 的参数
+// 多个值使用逗号连接
+func argValuesMap(defs []*ArgDef, values [][]string) map[string]string {
+	args := make(map[string]string, len(defs))
+	for i, def := range defs {
+		if i < len(values) && len(values[i]) > 0 {
+			args[def.Name] = strings.Join(values[i], ",")
+		}
+	}
+	return args
+}
+
+// parseMultiTag 解析 multi tag
+// 格式: multi:"true" 或者 multi:"min,max", 省略的边界不做限制, 例如 multi:"1,"
+func parseMultiTag(tag string, def *ArgDef) {
+	if ok, err := strconv.ParseBool(tag); err == nil {
+		def.Multi = ok
+		return
+	}
+	def.Multi = true
+	min, max, _ := strings.Cut(tag, ",")
+	def.MinSelect, _ = strconv.Atoi(strings.TrimSpace(min))
+	def.MaxSelect, _ = strconv.Atoi(strings.TrimSpace(max))
+}
+
+// checkSelectCount 检查多选的数量
+func (def *ArgDef) checkSelectCount(n int) error {
+	if n < def.MinSelect {
+		return fmt.Errorf("select at least %d items", def.MinSelect)
+	}
+	if def.MaxSelect > 0 && n > def.MaxSelect {
+		return fmt.Errorf("select at most %d items", def.MaxSelect)
+	}
+	return nil
+}
+
+// splitMultiValues 拆分多选的值, 每个值可以是逗号分隔的列表
+func splitMultiValues(values []string) []string {
+	var items []string
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		items = append(items, strings.Split(value, ",")...)
+	}
+	return items
+}
+
+// multiSelect 交互多选参数值
+func (def *ArgDef) multiSelect(ctx blocks.Context, options []*completion.Suggest) ([]string, error) {
+	defaults := make(map[string]bool)
+	for _, v := range splitMultiValues([]string{def.Default}) {
+		defaults[v] = true
+	}
+	list := make([]string, 0, len(options))
+	opts := []blocks.SelectOption{
+		blocks.WithSelectOptionOptions(options...),
+		blocks.WithSelectOptionValid(func(sels []int) error {
+			return def.checkSelectCount(len(sels))
+		}),
+	}
+	var selected []int
+	for k, v := range options {
+		list = append(list, v.Text)
+		if defaults[v.Text] {
+			selected = append(selected, k)
+		}
+	}
+	if len(selected) > 0 {
+		opts = append(opts, blocks.WithSelectOptionDefaults(selected...))
+	}
+	sels := ctx.RawMulSel(def.Prompt, list, opts...)
+	if sels == nil {
+		return nil, errUserCancel
+	}
+	values := make([]string, 0, len(sels))
+	for _, sel := range sels {
+		values = append(values, list[sel])
+	}
+	return values, nil
+}
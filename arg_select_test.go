@@ -0,0 +1,111 @@
+package promptx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/completion"
+)
+
+type selectTestArgs struct {
+	Server  string `arg:"server" selectfn:"testServers"`
+	Account string `arg:"account"`
+	Mode    string `arg:"mode" flag:"mode,m" select:"fast,safe"`
+}
+
+func TestSelectFuncSuggest(t *testing.T) {
+	RegisterSelectFunc("testServers", func(ctx blocks.Context, args map[string]string) []*completion.Suggest {
+		return []*completion.Suggest{
+			{Text: "dev", Description: "development"},
+			{Text: "test"},
+		}
+	})
+	login := NewCommandWithFunc("login", "login server", func(ctx Context, arg *selectTestArgs) {})
+	login.ArgSelect("Account", func(ctx blocks.Context, args map[string]string) []*completion.Suggest {
+		return []*completion.Suggest{{Text: args["Server"] + "-admin"}, {Text: args["Server"] + "-guest"}}
+	})
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(login)
+
+	var scenarioTable = []struct {
+		line     string
+		expected []string
+	}{
+		{line: "login ", expected: []string{"dev", "test"}},
+		{line: "login te", expected: []string{"test"}},
+		{line: "login dev ", expected: []string{"dev-admin", "dev-guest"}},
+		{line: "login -m fast test g", expected: []string{"test-guest"}},
+		{line: "login dev admin ", expected: nil},
+		{line: "login --mode ", expected: []string{"fast", "safe"}},
+		{line: "login --mode=sa", expected: []string{"--mode=safe"}},
+	}
+	for _, s := range scenarioTable {
+		var actual []string
+		for _, v := range root.FindSuggest(*buffer.NewDocumentWithCursor(s.line, len([]rune(s.line)))) {
+			actual = append(actual, v.Text)
+		}
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %#v, but got %#v", s.line, s.expected, actual)
+		}
+	}
+
+	suggests := root.FindSuggest(*buffer.NewDocumentWithCursor("login ", 6))
+	if suggests[0].Description != "development" || suggests[1].Description != "server" {
+		t.Errorf("description should fallback to prompt, but got %q %q", suggests[0].Description, suggests[1].Description)
+	}
+}
+
+type multiSelectTestArgs struct {
+	Tags  []string `arg:"tags" select:"red,green,blue" multi:"1,2"`
+	Roles []string `arg:"roles" select:"admin,guest" multi:"true" flag:"role,r"`
+}
+
+func TestMultiSelect(t *testing.T) {
+	defs := parseArgDefs(&multiSelectTestArgs{})
+	if !defs[0].Multi || defs[0].MinSelect != 1 || defs[0].MaxSelect != 2 {
+		t.Errorf("parse multi:\"1,2\" failed: %+v", defs[0])
+	}
+	if !defs[1].Multi || defs[1].MinSelect != 0 || defs[1].MaxSelect != 0 {
+		t.Errorf("parse multi:\"true\" failed: %+v", defs[1])
+	}
+
+	var scenarioTable = []struct {
+		args     []string
+		expected [][]string
+		err      bool
+	}{
+		{args: []string{"red,blue"}, expected: [][]string{{"red,blue"}, nil}},
+		{args: []string{"red", "blue", "-r", "admin", "-r", "guest"}, expected: [][]string{{"red", "blue"}, {"admin", "guest"}}},
+		{args: []string{"red,green,blue"}, err: true},
+		{args: []string{"red", "green", "blue"}, err: true},
+	}
+	for _, s := range scenarioTable {
+		actual, err := checkArgs(nil, defs, s.args)
+		if s.err {
+			if err == nil {
+				t.Errorf("%v: should return error", s.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", s.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%v: should be %#v, but got %#v", s.args, s.expected, actual)
+		}
+	}
+
+	cmd := NewCommandWithFunc("paint", "paint", func(ctx Context, arg *multiSelectTestArgs) {})
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(cmd)
+	var actual []string
+	for _, v := range root.FindSuggest(*buffer.NewDocumentWithCursor("paint red,b", 11)) {
+		actual = append(actual, v.Text)
+	}
+	if !reflect.DeepEqual(actual, []string{"red,blue"}) {
+		t.Errorf("should complete last item, but got %#v", actual)
+	}
+}
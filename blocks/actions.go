@@ -0,0 +1,269 @@
+package blocks
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/aggronmagi/promptx/v2/input"
+	"github.com/aggronmagi/promptx/v2/internal/debug"
+)
+
+// Names of the actions provided by block manager. They operate the history and
+// completion of the manager, so they are not in the action registry.
+const (
+	ActionPreviousHistory       = "previous-history"
+	ActionNextHistory           = "next-history"
+	ActionHistorySearchBackward = "history-search-backward"
+	ActionHistorySearchForward  = "history-search-forward"
+	ActionComplete              = "complete"
+	ActionMenuComplete          = "menu-complete"
+	ActionMenuCompleteBackward  = "menu-complete-backward"
+	ActionReverseSearchHistory  = "reverse-search-history"
+	ActionForwardSearchHistory  = "forward-search-history"
+)
+
+var managerActions = []string{
+	ActionPreviousHistory,
+	ActionNextHistory,
+	ActionHistorySearchBackward,
+	ActionHistorySearchForward,
+	ActionComplete,
+	ActionMenuComplete,
+	ActionMenuCompleteBackward,
+	ActionReverseSearchHistory,
+	ActionForwardSearchHistory,
+}
+
+// ActionBind bind key to a named editor action, or a macro inserting text.
+type ActionBind struct {
+	Key input.Key
+	// Action name of the action, like readline function names "beginning-of-line"
+	Action string
+	// Macro text to insert, used when Action is empty
+	Macro string
+}
+
+func (b ActionBind) String() string {
+	if b.Action == "" {
+		return fmt.Sprintf("%s: %q", b.Key, b.Macro)
+	}
+	return fmt.Sprintf("%s: %s", b.Key, b.Action)
+}
+
+var (
+	actionsMu sync.RWMutex
+	actions   = map[string]KeyBindFunc{
+		"beginning-of-line":    actionBeginningOfLine,
+		"end-of-line":          actionEndOfLine,
+		"forward-char":         actionForwardChar,
+		"backward-char":        actionBackwardChar,
+		"forward-word":         actionForwardWord,
+		"backward-word":        actionBackwardWord,
+		"delete-char":          actionDeleteChar,
+		"delete-char-or-eof":   actionDeleteCharOrEOF,
+		"backward-delete-char": actionBackwardDeleteChar,
+		"kill-line":            actionKillLine,
+		"unix-line-discard":    actionUnixLineDiscard,
+		"backward-kill-line":   actionUnixLineDiscard,
+		"kill-word":            actionKillWord,
+		"unix-word-rubout":     actionUnixWordRubout,
+		"backward-kill-word":   actionUnixWordRubout,
+		"transpose-chars":      actionTransposeChars,
+		"yank":                 actionYank,
+		"yank-pop":             actionYankPop,
+		"undo":                 actionUndo,
+		"redo":                 actionRedo,
+		"clear-screen":         actionClearScreen,
+	}
+)
+
+// RegisterAction register a named editor action, then it could be bound to keys
+// by name. register an existing name replaces the action.
+func RegisterAction(name string, fn KeyBindFunc) {
+	actionsMu.Lock()
+	defer actionsMu.Unlock()
+	actions[name] = fn
+}
+
+// LookupAction get the registered editor action
+func LookupAction(name string) (fn KeyBindFunc, ok bool) {
+	actionsMu.RLock()
+	defer actionsMu.RUnlock()
+	fn, ok = actions[name]
+	return
+}
+
+// HasAction reports whether name is a registered action or an action provided by block manager.
+func HasAction(name string) bool {
+	_, ok := LookupAction(name)
+	return ok || slices.Contains(managerActions, name)
+}
+
+// ActionNames names of all actions, sorted.
+func ActionNames() []string {
+	actionsMu.RLock()
+	names := make([]string, 0, len(actions)+len(managerActions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	actionsMu.RUnlock()
+	names = append(names, managerActions...)
+	slices.Sort(names)
+	return names
+}
+
+// actionFunc get the function of bind, local are the actions provided by block manager.
+func actionFunc(bind ActionBind, local map[string]KeyBindFunc) (fn KeyBindFunc, isLocal, ok bool) {
+	if bind.Action == "" {
+		return macroAction(bind.Macro), false, true
+	}
+	if fn, ok = local[bind.Action]; ok {
+		return fn, true, true
+	}
+	if fn, ok = LookupAction(bind.Action); !ok {
+		debug.Println("ignore unknown action", bind)
+	}
+	return fn, false, ok
+}
+
+// macroAction insert text
+func macroAction(text string) KeyBindFunc {
+	return func(ctx PressContext) bool {
+		ctx.GetBuffer().InsertText(text, false, true)
+		return false
+	}
+}
+
+// Go to the beginning of the line
+func actionBeginningOfLine(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	x := []rune(buf.Document().TextBeforeCursor())
+	buf.CursorLeft(len(x))
+	return false
+}
+
+// Go to the End of the line
+func actionEndOfLine(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	x := []rune(buf.Document().TextAfterCursor())
+	buf.CursorRight(len(x))
+	return false
+}
+
+// Forward one character
+func actionForwardChar(ctx PressContext) bool {
+	ctx.GetBuffer().CursorRight(1)
+	return false
+}
+
+// Backward one character
+func actionBackwardChar(ctx PressContext) bool {
+	ctx.GetBuffer().CursorLeft(1)
+	return false
+}
+
+// Forward to the end of the next word
+func actionForwardWord(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	buf.CursorRight(len([]rune(buf.Document().GetWordAfterCursorWithSpace())))
+	return false
+}
+
+// Back to the start of the current or previous word
+func actionBackwardWord(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	buf.CursorLeft(len([]rune(buf.Document().GetWordBeforeCursorWithSpace())))
+	return false
+}
+
+// Delete character under the cursor
+func actionDeleteChar(ctx PressContext) bool {
+	ctx.GetBuffer().Delete(1)
+	return false
+}
+
+// Delete character under the cursor, exit on empty line
+func actionDeleteCharOrEOF(ctx PressContext) bool {
+	if ctx.GetBuffer().Text() == "" {
+		// use control-d exit
+		return true
+	}
+	ctx.GetBuffer().Delete(1)
+	return false
+}
+
+// Delete character before the cursor
+func actionBackwardDeleteChar(ctx PressContext) bool {
+	ctx.GetBuffer().DeleteBeforeCursor(1)
+	return false
+}
+
+// Cut the Line after the cursor
+func actionKillLine(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	x := []rune(buf.Document().TextAfterCursor())
+	sharedKillRing.kill(buf, len(x), false)
+	return false
+}
+
+// Cut the Line before the cursor
+func actionUnixLineDiscard(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	x := []rune(buf.Document().TextBeforeCursor())
+	sharedKillRing.kill(buf, len(x), true)
+	return false
+}
+
+// Cut the Word after the cursor
+func actionKillWord(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	sharedKillRing.kill(buf, len([]rune(buf.Document().GetWordAfterCursorWithSpace())), false)
+	return false
+}
+
+// Cut the Word before the cursor
+func actionUnixWordRubout(ctx PressContext) bool {
+	buf := ctx.GetBuffer()
+	sharedKillRing.kill(buf, len([]rune(buf.Document().GetWordBeforeCursorWithSpace())), true)
+	return false
+}
+
+// Swap the last two characters before the cursor
+func actionTransposeChars(ctx PressContext) bool {
+	ctx.GetBuffer().SwapCharactersBeforeCursor()
+	return false
+}
+
+// Paste the last thing to be cut
+func actionYank(ctx PressContext) bool {
+	sharedKillRing.yank(ctx.GetBuffer())
+	return false
+}
+
+// Replace the yanked text with the older kill
+func actionYankPop(ctx PressContext) bool {
+	sharedKillRing.yankPop(ctx.GetBuffer())
+	return false
+}
+
+// Undo the last edit
+func actionUndo(ctx PressContext) bool {
+	ctx.GetBuffer().Undo()
+	return false
+}
+
+// Redo the undone edit
+func actionRedo(ctx PressContext) bool {
+	ctx.GetBuffer().Redo()
+	return false
+}
+
+// Clear the Screen, similar to the clear command
+func actionClearScreen(ctx PressContext) bool {
+	out := ctx.Writer()
+	out.EraseScreen()
+	out.CursorGoTo(0, 0)
+	debug.AssertNoError(out.Flush())
+	return false
+}
@@ -1,9 +1,11 @@
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	completion "github.com/aggronmagi/promptx/v2/completion"
 	"github.com/aggronmagi/promptx/v2/input"
@@ -33,6 +35,8 @@ func promptxBlocksOptions() interface{} {
 		"Stderr": output.ConsoleWriter(output.NewStderrWriter()),
 		// Context
 		"Context": Context(nil),
+		// time to wait the canceled task to return, see RunTask
+		"TaskGracePeriod": time.Duration(time.Second),
 	}
 }
 
@@ -83,7 +87,12 @@ type Interaction interface {
 }
 
 // Context Run Command Context
+//
+// Context implements context.Context. While a command is running by
+// Application.RunTask, Done is closed when SIGINT or the interrupt key
+// is received.
 type Context interface {
+	context.Context
 	Terminal
 	Interaction
 }
@@ -95,6 +104,9 @@ type Controler interface {
 	AddHistory(line string)
 	// reset history file
 	ResetHistoryFile(filename string)
+	// SwitchHistoryFile save current history and switch to the history of filename.
+	// the history of each file is kept in memory, switch back restores records and cursor.
+	SwitchHistoryFile(filename string)
 
 	GetPresetInputOptions() *InputOptions
 	GetPresetSelectOptions() *SelectOptions
@@ -104,6 +116,10 @@ type Application interface {
 	Context
 	Controler
 	Run() error
+	// RunTask run task with a cancelable context. The context is canceled
+	// when SIGINT or the interrupt key is received while the task is running,
+	// RunTask waits the task to return at most TaskGracePeriod, then returns context.Canceled.
+	RunTask(task func(ctx context.Context) error) error
 	// GetManager returns the BlocksManager used by this application.
 	// This allows external packages to access the manager for configuration.
 	GetManager() BlocksManager
@@ -141,6 +157,7 @@ func newApplication(cc *BlocksOptions) *application {
 		cc.Manager = NewDefaultBlockManger(cc.Common...)
 	}
 	app.console = terminal.NewTerminalApp(cc.Input)
+	app.console.SetInterruptKey(NewCommonOptions(cc.Common...).Cancel)
 	cc.Manager.SetWriter(cc.Output)
 	cc.Manager.SetExecContext(cc.Context)
 	cc.Manager.UpdateWinSize(cc.Input.GetWinSize())
@@ -260,6 +277,61 @@ func (p *application) Run() error {
 	return nil
 }
 
+// RunTask run task with a cancelable context
+func (p *application) RunTask(task func(ctx context.Context) error) error {
+	return p.runTask(context.Background(), task)
+}
+
+// runTask run task with a context derived from parent
+func (p *application) runTask(parent context.Context, task func(ctx context.Context) error) error {
+	ctx, end := p.console.StartTask(parent)
+	defer end()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- task(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		debug.Println("task canceled")
+	}
+	// the task may still change the state of application, wait it to return
+	timer := time.NewTimer(p.cc.TaskGracePeriod)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		debug.Println("canceled task not return in grace period")
+	}
+	return ctx.Err()
+}
+
+// Deadline application context never canceled. see RunTask.
+func (p *application) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+// Done application context never canceled. see RunTask.
+func (p *application) Done() <-chan struct{} {
+	return nil
+}
+
+// Err application context never canceled. see RunTask.
+func (p *application) Err() error {
+	return nil
+}
+
+// Value application context has no values.
+func (p *application) Value(key any) any {
+	return nil
+}
+
 // EnterRawMode enter raw mode for read key press real time
 func (p *application) EnterRawMode() error {
 	return p.console.EnterRaw()
@@ -386,6 +458,15 @@ func (p *application) ResetHistoryFile(filename string) {
 	}
 }
 
+// SwitchHistoryFile save current history and switch to the history of filename
+func (p *application) SwitchHistoryFile(filename string) {
+	if iface, ok := p.cc.Manager.(interface {
+		SwitchHistoryFile(fname string)
+	}); ok {
+		iface.SwitchHistoryFile(filename)
+	}
+}
+
 // GetPresetInputOptions get preset input options
 func (p *application) GetPresetInputOptions() *InputOptions {
 	return p.inputCC
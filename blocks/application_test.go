@@ -0,0 +1,55 @@
+package blocks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+func TestRunTaskIgnoreCancel(t *testing.T) {
+	var out bytes.Buffer
+	app := newApplication(NewBlocksOptions(
+		WithInput(testutil.NoTerminalParser{}),
+		WithOutput(output.NewConsoleWriter(&out)),
+		WithTaskGracePeriod(time.Second),
+	))
+
+	// 任务忽略取消, 在等待时间内返回
+	parent, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	returned := false
+	go func() {
+		<-started
+		cancel()
+	}()
+	err := app.runTask(parent, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		returned = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || !returned {
+		t.Errorf("should wait the canceled task to return, but got %v %v", err, returned)
+	}
+
+	// 任务一直不返回, 超过等待时间后返回
+	app.cc.TaskGracePeriod = 20 * time.Millisecond
+	parent, cancel = context.WithCancel(context.Background())
+	cancel()
+	release := make(chan struct{})
+	defer close(release)
+	begin := time.Now()
+	err = app.runTask(parent, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || time.Since(begin) > time.Second {
+		t.Errorf("should return after grace period, but got %v in %v", err, time.Since(begin))
+	}
+}
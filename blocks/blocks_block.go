@@ -20,6 +20,48 @@ type ConsoleBlocks interface {
 	GetBuffer() *buffer.Buffer
 }
 
+// EditorBlocks input line editor blocks. BlocksEmacsBuffer or BlocksViBuffer.
+type EditorBlocks interface {
+	ConsoleBlocks
+	IsBind(key input.Key) bool
+	BindKey(bind KeyBindFunc, keys ...input.Key)
+	UnbindKey(keys ...input.Key)
+	// SetRedoKey set the key to redo the undone edit
+	SetRedoKey(key input.Key)
+	// InsertTyped insert the typed characters into buf.
+	// the editor decides whether typed characters are text (vi normal mode are commands).
+	InsertTyped(buf *buffer.Buffer, in []byte)
+}
+
+// EditMode key bindings of the input line editor
+type EditMode int
+
+const (
+	// EditModeEmacs emacs key bindings (default)
+	EditModeEmacs EditMode = iota
+	// EditModeVi vi key bindings
+	EditModeVi
+)
+
+func (m EditMode) String() string {
+	switch m {
+	case EditModeEmacs:
+		return "emacs"
+	case EditModeVi:
+		return "vi"
+	}
+	return "unknown"
+}
+
+// NewEditorBlocks create input line editor for mode.
+// indicator shows the vi mode, it is ignored by emacs editor and could be nil.
+func NewEditorBlocks(mode EditMode, indicator *BlocksWords) EditorBlocks {
+	if mode == EditModeVi {
+		return &BlocksViBuffer{Indicator: indicator}
+	}
+	return &BlocksEmacsBuffer{}
+}
+
 // KeyBindFunc receives context and process
 type KeyBindFunc func(ctx PressContext) (exit bool)
 
@@ -118,6 +160,13 @@ func (m *EmptyBlocks) BindKey(bind KeyBindFunc, keys ...input.Key) {
 	}
 }
 
+// UnbindKey remove key funcs
+func (m *EmptyBlocks) UnbindKey(keys ...input.Key) {
+	for _, key := range keys {
+		delete(m.keyBind, key)
+	}
+}
+
 // BindASCII bind ascii code func
 func (m *EmptyBlocks) BindASCII(bind KeyBindFunc, ins ...byte) {
 	if m.keyBind == nil {
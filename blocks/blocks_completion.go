@@ -75,13 +75,7 @@ func (c *BlocksCompletion) InitBlocks() {
 	c.ApplyOptions()
 
 	c.BindKey(c.resetCompletion, input.Escape)
-	c.BindKey(func(ctx PressContext) (exit bool) {
-		comp := c.Completions
-		if len(comp.GetSuggestions()) > 0 {
-			comp.Previous()
-		}
-		return
-	}, input.BackTab)
+	c.BindKey(c.previousCompletion, input.BackTab)
 	c.BindKey(c.tabCompletion, input.Tab)
 	c.BindKey(c.refreshCompletion, input.Key(input.NotDefined))
 	for _, v := range emacsKeyBindings {
@@ -225,6 +219,15 @@ func (c *BlocksCompletion) refreshCompletion(ctx PressContext) (exit bool) {
 	return
 }
 
+// previousCompletion select the previous suggestion
+func (c *BlocksCompletion) previousCompletion(ctx PressContext) (exit bool) {
+	comp := c.Completions
+	if comp != nil && len(comp.GetSuggestions()) > 0 {
+		comp.Previous()
+	}
+	return
+}
+
 func (c *BlocksCompletion) tabCompletion(ctx PressContext) (exit bool) {
 	if !c.Active() || c.Completions == nil {
 		return
@@ -239,6 +242,7 @@ func (c *BlocksCompletion) tabCompletion(ctx PressContext) (exit bool) {
 		return
 	case 1:
 		buf := ctx.GetBuffer()
+		buf.BeginEditGroup()
 		w := buf.Document().GetWordBeforeCursorUntilSeparator(c.Completions.WordSeparator)
 		if w != "" {
 			buf.DeleteBeforeCursor(len([]rune(w)))
@@ -248,6 +252,7 @@ func (c *BlocksCompletion) tabCompletion(ctx PressContext) (exit bool) {
 		} else {
 			buf.InsertText(suggestions[0].Text, false, true)
 		}
+		buf.EndEditGroup()
 
 		c.Update(ctx.GetBuffer().Document())
 	default:
@@ -264,6 +269,8 @@ func (c *BlocksCompletion) EnterSelect(buf *buffer.Buffer) (ok bool) {
 	if s == nil {
 		return
 	}
+	buf.BeginEditGroup()
+	defer buf.EndEditGroup()
 	w := buf.Document().GetWordBeforeCursorUntilSeparator(c.Completions.WordSeparator)
 	if w != "" {
 		buf.DeleteBeforeCursor(len([]rune(w)))
@@ -3,7 +3,6 @@ package blocks
 import (
 	buffer "github.com/aggronmagi/promptx/v2/buffer"
 	"github.com/aggronmagi/promptx/v2/input"
-	"github.com/aggronmagi/promptx/v2/internal/debug"
 	"github.com/aggronmagi/promptx/v2/output"
 	runewidth "github.com/mattn/go-runewidth"
 )
@@ -30,16 +29,21 @@ import (
 // | [x] | Ctrl + L | Clear the Screen, similar to the clear command          |
 // | [x] | Ctrl + d | Delete character under the cursor                       |
 // | [x] | Ctrl + h | Delete character before the cursor (Backspace)          |
-// | [x] | Ctrl + w | Cut the Word before the cursor to the clipboard.        |
-// | [x] | Ctrl + k | Cut the Line after the cursor to the clipboard.         |
-// | [x] | Ctrl + u | Cut/delete the Line before the cursor to the clipboard. |
+// | [x] | Ctrl + w | Cut the Word before the cursor to the kill ring.        |
+// | [x] | Ctrl + k | Cut the Line after the cursor to the kill ring.         |
+// | [x] | Ctrl + u | Cut/delete the Line before the cursor to the kill ring. |
 // | [ ] | Ctrl + t | Swap the last two characters before the cursor (typo).  |
 // | [ ] | Esc  + t | Swap the last two words before the cursor.              |
-// | [ ] | ctrl + y | Paste the last thing to be cut (yank)                   |
-// | [ ] | ctrl + _ | Undo                                                    |
+// | [x] | ctrl + y | Paste the last thing to be cut (yank)                   |
+// | [x] | Meta + y | Replace the yanked text with the older kill (yank-pop)  |
+// | [x] | ctrl + _ | Undo                                                    |
+// | [x] | Meta + Z | Redo (Meta + Shift + z, configurable by SetRedoKey)     |
 type BlocksEmacsBuffer struct {
 	EmptyBlocks
 	buf *buffer.Buffer
+	// redo key
+	redoKey    input.Key
+	hasRedoKey bool
 	// colors
 	TextColor output.Color
 	BGColor   output.Color
@@ -69,9 +73,30 @@ func (c *BlocksEmacsBuffer) InitBlocks() {
 	for _, v := range commonKeyBindings {
 		c.BindKey(v.Fn, v.Key)
 	}
+	if !c.hasRedoKey {
+		c.redoKey, c.hasRedoKey = input.MetaShiftZ, true
+	}
+	c.BindKey(actionRedo, c.redoKey)
 	c.init = true
 }
 
+// SetRedoKey set the key to redo the edit undone by Ctrl + _
+func (c *BlocksEmacsBuffer) SetRedoKey(key input.Key) {
+	if c.hasRedoKey && c.redoKey == key {
+		return
+	}
+	if c.init {
+		delete(c.keyBind, c.redoKey)
+		c.BindKey(actionRedo, key)
+	}
+	c.redoKey, c.hasRedoKey = key, true
+}
+
+// InsertTyped insert the typed characters into buf
+func (c *BlocksEmacsBuffer) InsertTyped(buf *buffer.Buffer, in []byte) {
+	buf.InsertText(string(in), false, true)
+}
+
 // Render render to console
 func (c *BlocksEmacsBuffer) Render(ctx PrintContext, preCursor int) int {
 	if c.buf == nil {
@@ -93,176 +118,48 @@ func (c *BlocksEmacsBuffer) Render(ctx PrintContext, preCursor int) int {
 
 var emacsKeyBindings = []KeyBind{
 	// Go to the End of the line
-	{
-		Key: input.ControlE,
-		Fn: func(ctx PressContext) bool {
-			x := []rune(ctx.GetBuffer().Document().TextAfterCursor())
-			ctx.GetBuffer().CursorRight(len(x))
-			return false
-		},
-	},
+	{Key: input.ControlE, Fn: actionEndOfLine},
 	// Go to the beginning of the line
-	{
-		Key: input.ControlA,
-		Fn: func(ctx PressContext) bool {
-			x := []rune(ctx.GetBuffer().Document().TextBeforeCursor())
-			ctx.GetBuffer().CursorLeft(len(x))
-			return false
-		},
-	},
+	{Key: input.ControlA, Fn: actionBeginningOfLine},
 	// Cut the Line after the cursor
-	{
-		Key: input.ControlK,
-		Fn: func(ctx PressContext) bool {
-			x := []rune(ctx.GetBuffer().Document().TextAfterCursor())
-			ctx.GetBuffer().Delete(len(x))
-			return false
-		},
-	},
+	{Key: input.ControlK, Fn: actionKillLine},
 	// Cut/delete the Line before the cursor
-	{
-		Key: input.ControlU,
-		Fn: func(ctx PressContext) bool {
-			x := []rune(ctx.GetBuffer().Document().TextBeforeCursor())
-			ctx.GetBuffer().DeleteBeforeCursor(len(x))
-			return false
-		},
-	},
-	// Delete character under the cursor
-	{
-		Key: input.ControlD,
-		Fn: func(ctx PressContext) bool {
-			if ctx.GetBuffer().Text() != "" {
-				ctx.GetBuffer().Delete(1)
-			} else {
-				// use control-d exit
-				return true
-			}
-			return false
-		},
-	},
+	{Key: input.ControlU, Fn: actionUnixLineDiscard},
+	// Delete character under the cursor, use control-d exit
+	{Key: input.ControlD, Fn: actionDeleteCharOrEOF},
 	// Backspace
-	{
-		Key: input.ControlH,
-		Fn: func(ctx PressContext) bool {
-			ctx.GetBuffer().DeleteBeforeCursor(1)
-			return false
-		},
-	},
+	{Key: input.ControlH, Fn: actionBackwardDeleteChar},
 	// Left word arrow:
-	{
-		Key: input.MetaB,
-		Fn: func(ctx PressContext) bool {
-			buf := ctx.GetBuffer()
-			doc := buf.Document()
-			buf.CursorLeft(len([]rune(doc.GetWordBeforeCursorWithSpace())))
-			return false
-		},
-	},
+	{Key: input.MetaB, Fn: actionBackwardWord},
 	// right word arrow:
-	{
-		Key: input.MetaF,
-		Fn: func(ctx PressContext) bool {
-			buf := ctx.GetBuffer()
-			doc := buf.Document()
-			buf.CursorRight(len([]rune(doc.GetWordAfterCursorWithSpace())))
-			return false
-		},
-	},
+	{Key: input.MetaF, Fn: actionForwardWord},
 	// Right allow: Forward one character
-	{
-		Key: input.ControlF,
-		Fn: func(ctx PressContext) bool {
-			ctx.GetBuffer().CursorRight(1)
-			return false
-		},
-	},
+	{Key: input.ControlF, Fn: actionForwardChar},
 	// Left allow: Backward one character
-	{
-		Key: input.ControlB,
-		Fn: func(ctx PressContext) bool {
-			ctx.GetBuffer().CursorLeft(1)
-			return false
-		},
-	},
+	{Key: input.ControlB, Fn: actionBackwardChar},
 	// Cut the Word before the cursor.
-	{
-		Key: input.ControlW,
-		Fn: func(ctx PressContext) bool {
-			ctx.GetBuffer().DeleteBeforeCursor(len([]rune(
-				ctx.GetBuffer().Document().GetWordBeforeCursorWithSpace(),
-			)))
-			return false
-		},
-	},
+	{Key: input.ControlW, Fn: actionUnixWordRubout},
+	// Paste the last thing to be cut
+	{Key: input.ControlY, Fn: actionYank},
+	// Replace the yanked text with the older kill
+	{Key: input.MetaY, Fn: actionYankPop},
+	// Undo
+	{Key: input.ControlUnderscore, Fn: actionUndo},
 	// Clear the Screen, similar to the clear command
-	{
-		Key: input.ControlL,
-		Fn: func(ctx PressContext) bool {
-			out := ctx.Writer()
-			out.EraseScreen()
-			out.CursorGoTo(0, 0)
-			debug.AssertNoError(out.Flush())
-			return false
-		},
-	},
+	{Key: input.ControlL, Fn: actionClearScreen},
 }
 
 var commonKeyBindings = []KeyBind{
 	// Go to the End of the line
-	{
-		Key: input.End,
-		Fn: func(ctx PressContext) (exit bool) {
-			buf := ctx.GetBuffer()
-			x := []rune(buf.Document().TextAfterCursor())
-			buf.CursorRight(len(x))
-			return
-		},
-	},
+	{Key: input.End, Fn: actionEndOfLine},
 	// Go to the beginning of the line
-	{
-		Key: input.Home,
-		Fn: func(ctx PressContext) (exit bool) {
-			buf := ctx.GetBuffer()
-			x := []rune(buf.Document().TextBeforeCursor())
-			buf.CursorLeft(len(x))
-			return
-		},
-	},
+	{Key: input.Home, Fn: actionBeginningOfLine},
 	// Delete character under the cursor
-	{
-		Key: input.Delete,
-		Fn: func(ctx PressContext) (exit bool) {
-			buf := ctx.GetBuffer()
-			buf.Delete(1)
-			return
-		},
-	},
+	{Key: input.Delete, Fn: actionDeleteChar},
 	// Backspace
-	{
-		Key: input.Backspace,
-		Fn: func(ctx PressContext) (exit bool) {
-			buf := ctx.GetBuffer()
-			buf.DeleteBeforeCursor(1)
-			return
-		},
-	},
+	{Key: input.Backspace, Fn: actionBackwardDeleteChar},
 	// Right allow: Forward one character
-	{
-		Key: input.Right,
-		Fn: func(ctx PressContext) (exit bool) {
-			buf := ctx.GetBuffer()
-			buf.CursorRight(1)
-			return
-		},
-	},
+	{Key: input.Right, Fn: actionForwardChar},
 	// Left allow: Backward one character
-	{
-		Key: input.Left,
-		Fn: func(ctx PressContext) (exit bool) {
-			buf := ctx.GetBuffer()
-			buf.CursorLeft(1)
-			return
-		},
-	},
+	{Key: input.Left, Fn: actionBackwardChar},
 }
@@ -0,0 +1,47 @@
+package blocks
+
+import (
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+func TestEmacsUndoRedo(t *testing.T) {
+	SharedKillRing().Clear()
+	defer SharedKillRing().Clear()
+
+	c := &BlocksEmacsBuffer{}
+	c.InitBlocks()
+	buf := c.GetBuffer()
+	press := func(key input.Key) {
+		c.OnEvent(&pressContext{buf: buf, key: key}, key, nil)
+	}
+	for _, r := range "git status" {
+		buf.InsertText(string(r), false, true)
+	}
+	press(input.ControlW)
+	press(input.ControlY)
+	press(input.ControlY)
+	if buf.Text() != "git statusstatus" {
+		t.Fatalf("text should be %q, but got %q", "git statusstatus", buf.Text())
+	}
+
+	for _, expected := range []string{"git status", "git ", "git status", "git "} {
+		press(input.ControlUnderscore)
+		if buf.Text() != expected {
+			t.Errorf("undo should be %q, but got %q", expected, buf.Text())
+		}
+	}
+	press(input.MetaShiftZ)
+	if buf.Text() != "git status" {
+		t.Errorf("redo should be %q, but got %q", "git status", buf.Text())
+	}
+
+	// 修改重做键
+	c.SetRedoKey(input.ControlR)
+	press(input.MetaShiftZ)
+	press(input.ControlR)
+	if buf.Text() != "git " {
+		t.Errorf("redo should be %q, but got %q", "git ", buf.Text())
+	}
+}
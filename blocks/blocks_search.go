@@ -0,0 +1,243 @@
+package blocks
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	buffer "github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/input"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+// BlocksHistorySearch incremental history search, like readline reverse-search-history.
+// it renders the "(reverse-i-search)'query': " prompt while searching,
+// the input buffer shows the matched history.
+//
+//	typing        append to the query, search from the current match
+//	Ctrl-R/Ctrl-S step to the older/newer match (keys bound by BindSearchKey)
+//	Backspace     remove the last character of the query
+//	Esc           accept the match, keep editing
+//	Ctrl-G        abort, restore the original line
+//	other keys    accept the match, then the key is processed normally
+type BlocksHistorySearch struct {
+	EmptyBlocks
+	// colors
+	TextColor output.Color
+	BGColor   output.Color
+	// Fuzzy match characters in order, otherwise match substring
+	Fuzzy bool
+
+	// search keys, value is search backward
+	searchKeys map[input.Key]bool
+
+	searching bool
+	backward  bool
+	failed    bool
+	query     []rune
+	lastQuery []rune
+	// history commands, oldest first
+	commands []string
+	// index of the current match in commands, len(commands) is the original line
+	index int
+	// line before searching
+	origin       string
+	originCursor int
+}
+
+// Searching is in searching
+func (c *BlocksHistorySearch) Searching() bool {
+	return c.searching
+}
+
+// Query current search string
+func (c *BlocksHistorySearch) Query() string {
+	return string(c.query)
+}
+
+// BindSearchKey bind keys to start searching, the keys step to the next match when searching.
+func (c *BlocksHistorySearch) BindSearchKey(bind KeyBindFunc, backward bool, keys ...input.Key) {
+	if c.searchKeys == nil {
+		c.searchKeys = map[input.Key]bool{}
+	}
+	c.BindKey(bind, keys...)
+	for _, key := range keys {
+		c.searchKeys[key] = backward
+	}
+}
+
+// UnbindKey remove key funcs
+func (c *BlocksHistorySearch) UnbindKey(keys ...input.Key) {
+	c.EmptyBlocks.UnbindKey(keys...)
+	for _, key := range keys {
+		delete(c.searchKeys, key)
+	}
+}
+
+// Start start searching commands, backward search older commands.
+func (c *BlocksHistorySearch) Start(buf *buffer.Buffer, commands []string, backward bool) {
+	c.searching = true
+	c.backward = backward
+	c.failed = false
+	c.query = c.query[:0]
+	c.commands = commands
+	c.index = len(commands)
+	c.origin = buf.Text()
+	c.originCursor = buf.Document().CursorPosition()
+}
+
+// Stop stop searching, abort restores the original line.
+func (c *BlocksHistorySearch) Stop(buf *buffer.Buffer, abort bool) {
+	if !c.searching {
+		return
+	}
+	if abort {
+		c.setLine(buf, c.origin, c.originCursor)
+	}
+	if len(c.query) > 0 {
+		c.lastQuery = append(c.lastQuery[:0], c.query...)
+	}
+	c.searching = false
+	c.commands = nil
+}
+
+// HandleEvent deal key press when searching.
+// handled is false if the search is accepted by key, the key should be processed by editor.
+func (c *BlocksHistorySearch) HandleEvent(buf *buffer.Buffer, key input.Key, in []byte) (handled bool) {
+	if !c.searching {
+		return false
+	}
+	if backward, ok := c.searchKeys[key]; ok {
+		// search again with the last search string, like readline
+		if len(c.query) == 0 {
+			c.query = append(c.query, c.lastQuery...)
+			c.backward = backward
+			c.search(buf, c.index, "")
+			return true
+		}
+		if c.backward != backward {
+			c.backward = backward
+			c.failed = false
+		}
+		c.search(buf, c.index+c.step(), buf.Text())
+		return true
+	}
+	switch key {
+	case input.Backspace, input.ControlH:
+		if len(c.query) > 0 {
+			c.query = c.query[:len(c.query)-1]
+			c.failed = false
+			c.search(buf, c.index, "")
+		}
+	case input.Escape:
+		c.Stop(buf, false)
+	case input.ControlG:
+		c.Stop(buf, true)
+	case input.NotDefined:
+		text := string(in)
+		if strings.IndexFunc(text, unicode.IsControl) >= 0 {
+			c.Stop(buf, false)
+			return false
+		}
+		c.query = append(c.query, []rune(text)...)
+		c.search(buf, c.index, "")
+	default:
+		c.Stop(buf, false)
+		return false
+	}
+	return true
+}
+
+func (c *BlocksHistorySearch) step() int {
+	if c.backward {
+		return -1
+	}
+	return 1
+}
+
+// search from index to the search direction, skip the command same as skip.
+func (c *BlocksHistorySearch) search(buf *buffer.Buffer, from int, skip string) {
+	if len(c.query) == 0 {
+		return
+	}
+	if c.backward && from >= len(c.commands) {
+		from = len(c.commands) - 1
+	}
+	for i := from; i >= 0 && i < len(c.commands); i += c.step() {
+		cmd := c.commands[i]
+		pos := c.match(cmd)
+		if pos < 0 || cmd == skip {
+			continue
+		}
+		c.index = i
+		c.failed = false
+		c.setLine(buf, cmd, pos)
+		return
+	}
+	c.failed = true
+}
+
+// match return the rune index of query in line, -1 if not matched.
+func (c *BlocksHistorySearch) match(line string) int {
+	if c.Fuzzy {
+		return fuzzyIndex([]rune(line), c.query)
+	}
+	i := strings.Index(line, string(c.query))
+	if i < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(line[:i])
+}
+
+// fuzzyIndex return the index of the first matched rune if sub matches line in order.
+func fuzzyIndex(line, sub []rune) int {
+	first, j := -1, 0
+	for i := 0; i < len(line) && j < len(sub); i++ {
+		if line[i] != sub[j] {
+			continue
+		}
+		if j == 0 {
+			first = i
+		}
+		j++
+	}
+	if j < len(sub) {
+		return -1
+	}
+	return first
+}
+
+func (c *BlocksHistorySearch) setLine(buf *buffer.Buffer, text string, cursor int) {
+	buf.Reset()
+	buf.InsertText(text, false, true)
+	buf.SetCursorPosition(cursor)
+}
+
+// prompt search prompt like bash
+func (c *BlocksHistorySearch) prompt() string {
+	var b strings.Builder
+	b.WriteString("(")
+	if c.failed {
+		b.WriteString("failed ")
+	}
+	if c.backward {
+		b.WriteString("reverse-")
+	}
+	b.WriteString("i-search)'")
+	b.WriteString(string(c.query))
+	b.WriteString("': ")
+	return b.String()
+}
+
+// Render render to console
+func (c *BlocksHistorySearch) Render(ctx PrintContext, preCursor int) int {
+	if !c.searching {
+		return preCursor
+	}
+	w := &Word{
+		Text:      c.prompt(),
+		TextColor: c.TextColor,
+		BGColor:   c.BGColor,
+	}
+	return w.Render(ctx, preCursor)
+}
@@ -0,0 +1,826 @@
+package blocks
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	buffer "github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/input"
+	"github.com/aggronmagi/promptx/v2/output"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// ViMode editing mode of BlocksViBuffer
+type ViMode int
+
+const (
+	// ViInsert typed characters are inserted as text
+	ViInsert ViMode = iota
+	// ViNormal typed characters are commands
+	ViNormal
+	// ViVisual commands operate on the selected text
+	ViVisual
+)
+
+func (m ViMode) String() string {
+	switch m {
+	case ViInsert:
+		return "insert"
+	case ViNormal:
+		return "normal"
+	case ViVisual:
+		return "visual"
+	}
+	return "unknown"
+}
+
+// ViModeWords mode indicator words, shown by BlocksViBuffer.Indicator
+var ViModeWords = map[ViMode]*Word{
+	ViInsert: {Text: "[I] ", TextColor: output.Green, BGColor: output.DefaultColor},
+	ViNormal: {Text: "[N] ", TextColor: output.Yellow, BGColor: output.DefaultColor},
+	ViVisual: {Text: "[V] ", TextColor: output.Purple, BGColor: output.DefaultColor},
+}
+
+// BlocksViBuffer vi operate like input buffer.
+// A new line starts in insert mode, Esc switches to normal mode.
+//
+// Insert mode
+// -----------
+// | ok  | key      | description                                             |
+// |-----+----------+---------------------------------------------------------|
+// | [x] | Esc      | Switch to normal mode                                   |
+// | [x] | Ctrl + h | Delete character before the cursor (Backspace)          |
+// | [x] | Ctrl + w | Cut the Word before the cursor to the kill ring.        |
+// | [x] | Ctrl + u | Cut/delete the Line before the cursor to the kill ring. |
+// | [x] | Ctrl + d | Delete character under the cursor, exit on empty line   |
+// | [x] | Ctrl + L | Clear the Screen, similar to the clear command          |
+//
+// Normal mode (commands accept a count, 3w d2w 2dd)
+// -------------------------------------------------
+// | ok  | key         | description                                          |
+// |-----+-------------+------------------------------------------------------|
+// | [x] | h l         | Backward/Forward one character                       |
+// | [x] | w b e       | Next word/Previous word/End of word                  |
+// | [x] | W B E       | Same as w b e, words are separated by blanks only    |
+// | [x] | 0 ^ $       | Beginning/First non-blank/End of the line            |
+// | [x] | f t F T     | To/Before the next/previous character                |
+// | [x] | ; ,         | Repeat the last f t F T, forward/backward            |
+// | [x] | d c y       | Delete/Change/Yank with a motion, dd cc yy the line  |
+// | [x] | x X D C s S | dl dh d$ c$ cl cc                                    |
+// | [x] | i a I A     | Insert before/after cursor, at first non-blank/end   |
+// | [x] | p P         | Paste the latest kill after/before the cursor        |
+// | [x] | r ~         | Replace character/Switch case                        |
+// | [x] | u Ctrl + r  | Undo/Redo (redo also the key of SetRedoKey)          |
+// | [x] | .           | Repeat the last change                               |
+// | [x] | v           | Switch to visual mode                                |
+//
+// Visual mode
+// -----------
+// | ok  | key     | description                                    |
+// |-----+---------+------------------------------------------------|
+// | [x] | motions | Extend the selection                           |
+// | [x] | d x     | Delete the selection                           |
+// | [x] | c s     | Change the selection                           |
+// | [x] | y       | Yank the selection                             |
+// | [x] | o       | Go to the other end of the selection           |
+// | [x] | v Esc   | Switch to normal mode                          |
+//
+// Deleted and yanked text is saved to the shared kill ring.
+type BlocksViBuffer struct {
+	EmptyBlocks
+	buf *buffer.Buffer
+	// Indicator show the current mode, nil means not show.
+	Indicator *BlocksWords
+	// redo key
+	redoKey    input.Key
+	hasRedoKey bool
+	// colors
+	TextColor output.Color
+	BGColor   output.Color
+	// selection colors of visual mode
+	SelectTextColor output.Color
+	SelectBGColor   output.Color
+
+	mode ViMode
+	// start of the selection in visual mode
+	anchor int
+	// uncompleted normal mode command
+	pending viPending
+	// last f t F T and its character
+	lastFind     rune
+	lastFindChar rune
+	// keys of current command, and the last change repeated by '.'
+	keys       []viEvent
+	lastChange []viEvent
+	// recording insert mode keys of the current change
+	recording bool
+	// replaying the last change
+	replaying bool
+	// the edits of current change are in one edit group
+	changing bool
+	init     bool
+}
+
+// viPending uncompleted normal mode command, like "2d3" "df"
+type viPending struct {
+	count   int
+	op      rune
+	opCount int
+	// f t F T r waiting for the character
+	char rune
+}
+
+type viEvent struct {
+	key input.Key
+	in  []byte
+}
+
+func (c *BlocksViBuffer) ResetBuffer() {
+	c.buf = buffer.NewBuffer()
+	c.pending = viPending{}
+	c.keys = nil
+	c.recording = false
+	c.changing = false
+	c.setMode(ViInsert)
+}
+
+func (c *BlocksViBuffer) GetBuffer() *buffer.Buffer {
+	return c.buf
+}
+
+func (c *BlocksViBuffer) InitBlocks() {
+	if c.init {
+		return
+	}
+	c.SetActive(true)
+	c.ResetBuffer()
+	for _, v := range emacsKeyBindings {
+		switch v.Key {
+		case input.ControlH, input.ControlW, input.ControlU, input.ControlD, input.ControlL:
+			c.BindKey(v.Fn, v.Key)
+		}
+	}
+	for _, v := range commonKeyBindings {
+		c.BindKey(v.Fn, v.Key)
+	}
+	if !c.hasRedoKey {
+		c.redoKey, c.hasRedoKey = input.MetaShiftZ, true
+	}
+	c.init = true
+}
+
+// SetRedoKey set the key to redo the edit undone by u, Ctrl + r is always bound.
+func (c *BlocksViBuffer) SetRedoKey(key input.Key) {
+	c.redoKey, c.hasRedoKey = key, true
+}
+
+// Mode current editing mode
+func (c *BlocksViBuffer) Mode() ViMode {
+	return c.mode
+}
+
+// InsertTyped insert the typed characters into buf in insert mode
+func (c *BlocksViBuffer) InsertTyped(buf *buffer.Buffer, in []byte) {
+	if c.mode != ViInsert || (len(in) > 0 && in[0] == 0x1b) {
+		return
+	}
+	buf.InsertText(string(in), false, true)
+}
+
+func (c *BlocksViBuffer) setMode(mode ViMode) {
+	c.mode = mode
+	if c.Indicator != nil {
+		c.Indicator.Words = []*Word{ViModeWords[mode]}
+	}
+}
+
+// OnEvent deal console key press
+func (c *BlocksViBuffer) OnEvent(ctx PressContext, key input.Key, in []byte) (exit bool) {
+	if !c.Active() || c.buf == nil {
+		return
+	}
+	if c.hasRedoKey && key == c.redoKey {
+		c.buf.Redo()
+		return
+	}
+	if c.mode == ViInsert {
+		return c.insertEvent(ctx, key, in)
+	}
+	return c.normalEvent(ctx, key, in)
+}
+
+func (c *BlocksViBuffer) insertEvent(ctx PressContext, key input.Key, in []byte) (exit bool) {
+	// Esc and the next key pressed quickly are parsed as one key
+	if r, ok := viMetaRune(key); ok {
+		c.insertEvent(ctx, input.Escape, nil)
+		return c.normalRune(ctx, r)
+	}
+	if key == input.NotDefined && len(in) > 1 && in[0] == 0x1b {
+		c.insertEvent(ctx, input.Escape, nil)
+		return c.normalEvent(ctx, key, in[1:])
+	}
+	if c.recording {
+		c.keys = append(c.keys, viEvent{key: key, in: slices.Clone(in)})
+	}
+	switch {
+	case key == input.Escape:
+		c.escape()
+		return
+	case key == input.NotDefined && c.replaying:
+		// the typed text is inserted by block manager, except replaying
+		c.buf.InsertText(string(in), false, true)
+		return
+	}
+	return c.EmptyBlocks.OnEvent(ctx, key, in)
+}
+
+// escape leave insert mode
+func (c *BlocksViBuffer) escape() {
+	if c.recording {
+		c.lastChange = c.keys
+		c.recording = false
+	}
+	c.endChange()
+	c.setMode(ViNormal)
+	c.buf.CursorLeft(1)
+}
+
+func (c *BlocksViBuffer) normalEvent(ctx PressContext, key input.Key, in []byte) (exit bool) {
+	if r, ok := viMetaRune(key); ok {
+		c.normalEvent(ctx, input.Escape, nil)
+		return c.normalRune(ctx, r)
+	}
+	switch key {
+	case input.NotDefined:
+		if len(in) > 0 && in[0] == 0x1b {
+			c.normalEvent(ctx, input.Escape, nil)
+			in = in[1:]
+		}
+		text := string(in)
+		for i, r := range text {
+			// text after a insert command, like pasted "ihello"
+			if c.mode == ViInsert {
+				return c.insertEvent(ctx, input.NotDefined, c.typeText(text[i:]))
+			}
+			c.normalRune(ctx, r)
+		}
+	case input.Escape:
+		c.pending = viPending{}
+		if c.mode == ViVisual {
+			c.setMode(ViNormal)
+		}
+	case input.ControlR:
+		c.undo(c.buf.Redo)
+	default:
+		if r, ok := viNormalKeys[key]; ok {
+			return c.normalRune(ctx, r)
+		}
+		// Ctrl + d/l and keys bound by BindKey
+		return c.EmptyBlocks.OnEvent(ctx, key, in)
+	}
+	return
+}
+
+// typeText insert text not inserted by block manager, return the text for recording
+func (c *BlocksViBuffer) typeText(text string) []byte {
+	if !c.replaying {
+		c.buf.InsertText(text, false, true)
+	}
+	return []byte(text)
+}
+
+// viNormalKeys keys have the same function of a normal mode command
+var viNormalKeys = map[input.Key]rune{
+	input.Left:      'h',
+	input.Backspace: 'h',
+	input.ControlH:  'h',
+	input.Right:     'l',
+	input.Home:      '0',
+	input.End:       '$',
+	input.Delete:    'x',
+}
+
+// viMetaRune character of Meta + key
+func viMetaRune(key input.Key) (rune, bool) {
+	switch {
+	case key >= input.MetaA && key <= input.MetaZ:
+		return 'a' + rune(key-input.MetaA), true
+	case key >= input.MetaShiftA && key <= input.MetaShiftZ:
+		return 'A' + rune(key-input.MetaShiftA), true
+	}
+	return 0, false
+}
+
+// normalRune deal a normal or visual mode character
+func (c *BlocksViBuffer) normalRune(ctx PressContext, r rune) (exit bool) {
+	if c.pending == (viPending{}) {
+		c.keys = nil
+	}
+	c.keys = append(c.keys, viEvent{key: input.NotDefined, in: []byte(string(r))})
+	done, change := c.command(ctx, r)
+	if !done {
+		return
+	}
+	c.pending = viPending{}
+	if change && !c.replaying {
+		if c.mode == ViInsert {
+			c.recording = true
+		} else {
+			c.lastChange = c.keys
+		}
+	}
+	if c.mode != ViInsert {
+		c.endChange()
+		c.clampCursor()
+	}
+	return
+}
+
+// command execute normal or visual mode command, done is false when the command is not completed.
+// change reports whether the command is repeatable by '.'.
+func (c *BlocksViBuffer) command(ctx PressContext, r rune) (done, change bool) {
+	p := &c.pending
+	// character of f t F T r
+	if p.char != 0 {
+		if p.char == 'r' {
+			return true, c.replace(r, p.count)
+		}
+		c.lastFind, c.lastFindChar = p.char, r
+		target, inclusive := c.find(p.char, r, c.count())
+		return c.move(p.char, target, inclusive)
+	}
+	// count
+	if r >= '1' && r <= '9' || r == '0' && (p.op == 0 && p.count > 0 || p.op != 0 && p.opCount > 0) {
+		if p.op == 0 {
+			p.count = p.count*10 + int(r-'0')
+		} else {
+			p.opCount = p.opCount*10 + int(r-'0')
+		}
+		return
+	}
+	switch r {
+	case 'f', 't', 'F', 'T':
+		p.char = r
+		return
+	case 'r':
+		if c.mode == ViNormal && p.op == 0 {
+			p.char = r
+			return
+		}
+	case 'd', 'c', 'y':
+		if c.mode == ViVisual {
+			c.operateSelection(r)
+			return true, false
+		}
+		if p.op == 0 {
+			p.op = r
+			return
+		}
+		if p.op != r {
+			return true, false
+		}
+		// dd cc yy operate the whole line
+		if r == 'y' {
+			c.yank(c.buf.Text())
+			return true, false
+		}
+		c.operate(r, 0, len([]rune(c.buf.Text())))
+		return true, true
+	}
+	if target, inclusive, ok := c.motion(r, c.count()); ok {
+		return c.move(r, target, inclusive)
+	}
+	if p.op != 0 {
+		// unknown motion cancel the operator
+		return true, false
+	}
+	if c.mode == ViVisual {
+		return true, c.visualCommand(r)
+	}
+	return true, c.normalCommand(ctx, r)
+}
+
+// count of current command
+func (c *BlocksViBuffer) count() int {
+	return max(c.pending.count, 1) * max(c.pending.opCount, 1)
+}
+
+// move the cursor to target, or operate the text between cursor and target
+func (c *BlocksViBuffer) move(motion rune, target int, inclusive bool) (done, change bool) {
+	op := c.pending.op
+	pos := c.buf.Document().CursorPosition()
+	if op == 0 {
+		c.buf.SetCursorPosition(target)
+		return true, false
+	}
+	// cw is the same as ce when the cursor is on a word
+	if op == 'c' && (motion == 'w' || motion == 'W') {
+		text := []rune(c.buf.Text())
+		if pos < len(text) && !unicode.IsSpace(text[pos]) {
+			// the word under cursor is the first word to change
+			target, inclusive = c.wordEnd(pos-1, motion == 'W', c.count()), true
+		}
+	}
+	from, to := min(pos, target), max(pos, target)
+	if inclusive {
+		to++
+	}
+	to = min(to, len([]rune(c.buf.Text())))
+	if op == 'y' {
+		c.yank(string([]rune(c.buf.Text())[from:to]))
+		c.buf.SetCursorPosition(from)
+		return true, false
+	}
+	c.operate(op, from, to)
+	return true, true
+}
+
+// operate delete or change the text between from and to
+func (c *BlocksViBuffer) operate(op rune, from, to int) {
+	c.beginChange()
+	c.buf.SetCursorPosition(from)
+	c.yank(c.buf.Delete(to - from))
+	if op == 'c' {
+		c.setMode(ViInsert)
+	}
+}
+
+// yank save text to the kill ring
+func (c *BlocksViBuffer) yank(text string) {
+	if len(text) > 0 {
+		sharedKillRing.Push(text)
+	}
+}
+
+func (c *BlocksViBuffer) normalCommand(ctx PressContext, r rune) (change bool) {
+	buf := c.buf
+	text := []rune(buf.Text())
+	pos := buf.Document().CursorPosition()
+	count := c.count()
+	switch r {
+	case 'i':
+		c.beginInsert()
+	case 'a':
+		c.beginInsert()
+		buf.CursorRight(1)
+	case 'I':
+		c.beginInsert()
+		buf.SetCursorPosition(firstNonBlank(text))
+	case 'A':
+		c.beginInsert()
+		buf.SetCursorPosition(len(text))
+	case 'x':
+		c.operate('d', pos, min(pos+count, len(text)))
+	case 'X':
+		c.operate('d', max(pos-count, 0), pos)
+	case 'D':
+		c.operate('d', pos, len(text))
+	case 'C':
+		c.operate('c', pos, len(text))
+	case 's':
+		c.operate('c', pos, min(pos+count, len(text)))
+	case 'S':
+		c.operate('c', 0, len(text))
+	case 'Y':
+		c.yank(string(text))
+		return false
+	case 'p', 'P':
+		latest, ok := sharedKillRing.Latest()
+		if !ok {
+			return false
+		}
+		c.beginChange()
+		if r == 'p' && len(text) > 0 {
+			buf.CursorRight(1)
+		}
+		buf.InsertText(strings.Repeat(latest, count), false, true)
+		buf.CursorLeft(1)
+	case '~':
+		c.beginChange()
+		for i := pos; i < min(pos+count, len(text)); i++ {
+			buf.Delete(1)
+			buf.InsertText(string(switchCase(text[i])), false, true)
+		}
+	case 'u':
+		c.undo(buf.Undo)
+		return false
+	case 'v':
+		c.anchor = pos
+		c.setMode(ViVisual)
+		return false
+	case '.':
+		c.repeat(ctx, count)
+		return false
+	default:
+		return false
+	}
+	return true
+}
+
+// beginInsert start a insert session, the edits until Esc are one change.
+func (c *BlocksViBuffer) beginInsert() {
+	c.beginChange()
+	c.setMode(ViInsert)
+}
+
+func (c *BlocksViBuffer) visualCommand(r rune) (change bool) {
+	switch r {
+	case 'x':
+		c.operateSelection('d')
+	case 's':
+		c.operateSelection('c')
+	case 'o':
+		pos := c.buf.Document().CursorPosition()
+		c.buf.SetCursorPosition(c.anchor)
+		c.anchor = pos
+	case 'v':
+		c.setMode(ViNormal)
+	}
+	// the selection is not recorded, visual commands are not repeatable
+	return false
+}
+
+// operateSelection delete/change/yank the selection of visual mode
+func (c *BlocksViBuffer) operateSelection(op rune) {
+	from, to := c.selection()
+	c.setMode(ViNormal)
+	if op == 'y' {
+		c.yank(string([]rune(c.buf.Text())[from:to]))
+		c.buf.SetCursorPosition(from)
+		return
+	}
+	c.operate(op, from, to)
+}
+
+// selection range of visual mode
+func (c *BlocksViBuffer) selection() (from, to int) {
+	pos := c.buf.Document().CursorPosition()
+	from, to = min(pos, c.anchor), max(pos, c.anchor)+1
+	return from, min(to, len([]rune(c.buf.Text())))
+}
+
+// replace count characters under the cursor with r
+func (c *BlocksViBuffer) replace(r rune, count int) (change bool) {
+	count = max(count, 1)
+	pos := c.buf.Document().CursorPosition()
+	if r < ' ' || pos+count > len([]rune(c.buf.Text())) {
+		return false
+	}
+	c.beginChange()
+	c.buf.Delete(count)
+	c.buf.InsertText(strings.Repeat(string(r), count), false, true)
+	c.buf.CursorLeft(1)
+	return true
+}
+
+// undo run undo/redo count times
+func (c *BlocksViBuffer) undo(fn func() bool) {
+	for range c.count() {
+		if !fn() {
+			break
+		}
+	}
+	c.pending = viPending{}
+	c.clampCursor()
+}
+
+// repeat the last change count times
+func (c *BlocksViBuffer) repeat(ctx PressContext, count int) {
+	if len(c.lastChange) == 0 {
+		return
+	}
+	c.pending = viPending{}
+	c.replaying = true
+	// repeated changes are undone as a whole
+	c.buf.BeginEditGroup()
+	defer func() {
+		c.buf.EndEditGroup()
+		c.replaying = false
+	}()
+	for range count {
+		for _, v := range c.lastChange {
+			c.OnEvent(ctx, v.key, v.in)
+		}
+	}
+}
+
+func (c *BlocksViBuffer) beginChange() {
+	if !c.changing {
+		c.changing = true
+		c.buf.BeginEditGroup()
+	}
+}
+
+func (c *BlocksViBuffer) endChange() {
+	if c.changing {
+		c.changing = false
+		c.buf.EndEditGroup()
+	}
+}
+
+// clampCursor keep the cursor on a character in normal and visual mode
+func (c *BlocksViBuffer) clampCursor() {
+	n := len([]rune(c.buf.Text()))
+	if c.buf.Document().CursorPosition() >= n {
+		c.buf.SetCursorPosition(n - 1)
+	}
+}
+
+// motion return the cursor position after moving, ok is false if r is not a motion.
+func (c *BlocksViBuffer) motion(r rune, count int) (target int, inclusive, ok bool) {
+	text := []rune(c.buf.Text())
+	pos := c.buf.Document().CursorPosition()
+	switch r {
+	case 'h':
+		return max(pos-count, 0), false, true
+	case 'l', ' ':
+		return min(pos+count, len(text)), false, true
+	case '0':
+		return 0, false, true
+	case '^':
+		return firstNonBlank(text), false, true
+	case '$':
+		return max(len(text)-1, 0), true, true
+	case 'w', 'W':
+		target = pos
+		for range count {
+			target = viNextWord(text, target, r == 'W')
+		}
+		return target, false, true
+	case 'b', 'B':
+		target = pos
+		for range count {
+			target = viPrevWord(text, target, r == 'B')
+		}
+		return target, false, true
+	case 'e', 'E':
+		return c.wordEnd(pos, r == 'E', count), true, true
+	case ';', ',':
+		if c.lastFind == 0 {
+			return pos, false, true
+		}
+		kind := c.lastFind
+		if r == ',' {
+			kind = reverseFind[kind]
+		}
+		target, inclusive = c.find(kind, c.lastFindChar, count)
+		return target, inclusive, true
+	}
+	return 0, false, false
+}
+
+var reverseFind = map[rune]rune{'f': 'F', 'F': 'f', 't': 'T', 'T': 't'}
+
+// wordEnd position of the count-th word end after pos
+func (c *BlocksViBuffer) wordEnd(pos int, big bool, count int) int {
+	text := []rune(c.buf.Text())
+	target := pos
+	for range count {
+		target = viWordEnd(text, target, big)
+	}
+	return target
+}
+
+// find the count-th character ch, f t forward and F T backward.
+// the cursor is not moved if ch is not found.
+func (c *BlocksViBuffer) find(kind, ch rune, count int) (target int, inclusive bool) {
+	text := []rune(c.buf.Text())
+	pos := c.buf.Document().CursorPosition()
+	switch kind {
+	case 'f', 't':
+		for i := pos + 1; i < len(text); i++ {
+			if text[i] != ch {
+				continue
+			}
+			if count--; count == 0 {
+				if kind == 't' {
+					i--
+				}
+				return i, true
+			}
+		}
+	case 'F', 'T':
+		for i := pos - 1; i >= 0; i-- {
+			if text[i] != ch {
+				continue
+			}
+			if count--; count == 0 {
+				if kind == 'T' {
+					i++
+				}
+				return i, false
+			}
+		}
+	}
+	return pos, false
+}
+
+// viRuneClass class of character: 0 blank, 1 word character, 2 punctuation.
+// big word only contains blank and non-blank.
+func viRuneClass(r rune, big bool) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case big || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	}
+	return 2
+}
+
+// viNextWord start of next word
+func viNextWord(text []rune, pos int, big bool) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	i := pos
+	if cls := viRuneClass(text[i], big); cls != 0 {
+		for i < len(text) && viRuneClass(text[i], big) == cls {
+			i++
+		}
+	}
+	for i < len(text) && viRuneClass(text[i], big) == 0 {
+		i++
+	}
+	return i
+}
+
+// viPrevWord start of the word before pos
+func viPrevWord(text []rune, pos int, big bool) int {
+	i := min(pos, len(text)) - 1
+	for i >= 0 && viRuneClass(text[i], big) == 0 {
+		i--
+	}
+	if i < 0 {
+		return 0
+	}
+	cls := viRuneClass(text[i], big)
+	for i > 0 && viRuneClass(text[i-1], big) == cls {
+		i--
+	}
+	return i
+}
+
+// viWordEnd end of the word after pos
+func viWordEnd(text []rune, pos int, big bool) int {
+	i := pos + 1
+	for i < len(text) && viRuneClass(text[i], big) == 0 {
+		i++
+	}
+	if i >= len(text) {
+		return max(len(text)-1, 0)
+	}
+	cls := viRuneClass(text[i], big)
+	for i+1 < len(text) && viRuneClass(text[i+1], big) == cls {
+		i++
+	}
+	return i
+}
+
+func firstNonBlank(text []rune) int {
+	for i, r := range text {
+		if !unicode.IsSpace(r) {
+			return i
+		}
+	}
+	return len(text)
+}
+
+func switchCase(r rune) rune {
+	if unicode.IsUpper(r) {
+		return unicode.ToLower(r)
+	}
+	return unicode.ToUpper(r)
+}
+
+// Render render to console
+func (c *BlocksViBuffer) Render(ctx PrintContext, preCursor int) int {
+	if c.buf == nil {
+		return preCursor
+	}
+	text := c.buf.Text()
+	ctx.SetBuffer(c.buf)
+	if ctx.Prepare() {
+		return runewidth.StringWidth(text) + preCursor
+	}
+	out := ctx.Writer()
+	if c.mode == ViVisual {
+		runes := []rune(text)
+		from, to := c.selection()
+		out.SetColor(c.TextColor, c.BGColor, false)
+		out.WriteStr(string(runes[:from]))
+		selectBG := c.SelectBGColor
+		if c.SelectTextColor == output.DefaultColor && selectBG == output.DefaultColor {
+			selectBG = output.LightGray
+		}
+		out.SetColor(c.SelectTextColor, selectBG, false)
+		out.WriteStr(string(runes[from:to]))
+		out.SetColor(c.TextColor, c.BGColor, false)
+		out.WriteStr(string(runes[to:]))
+	} else {
+		out.SetColor(c.TextColor, c.BGColor, false)
+		out.WriteStr(text)
+	}
+	out.SetColor(output.DefaultColor, output.DefaultColor, false)
+	return runewidth.StringWidth(text) + preCursor
+}
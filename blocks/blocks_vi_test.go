@@ -0,0 +1,171 @@
+package blocks
+
+import (
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+// viTester 模拟块管理器向 vi 输入框发送按键
+type viTester struct {
+	c *BlocksViBuffer
+}
+
+func newViTester() *viTester {
+	c := &BlocksViBuffer{Indicator: &BlocksWords{}}
+	c.InitBlocks()
+	return &viTester{c: c}
+}
+
+// typeKeys 逐个字符输入, 与块管理器一致先插入文本再分发事件
+func (v *viTester) typeKeys(keys string) {
+	for _, r := range keys {
+		if r == 0x1b {
+			v.press(input.Escape)
+			continue
+		}
+		in := []byte(string(r))
+		v.c.InsertTyped(v.c.GetBuffer(), in)
+		v.c.OnEvent(&pressContext{buf: v.c.GetBuffer(), key: input.NotDefined}, input.NotDefined, in)
+	}
+}
+
+func (v *viTester) press(key input.Key) {
+	v.c.OnEvent(&pressContext{buf: v.c.GetBuffer(), key: key}, key, nil)
+}
+
+func (v *viTester) check(t *testing.T, text string, cursor int) {
+	t.Helper()
+	buf := v.c.GetBuffer()
+	if buf.Text() != text || buf.Document().CursorPosition() != cursor {
+		t.Errorf("should be %q at %d, but got %q at %d", text, cursor, buf.Text(), buf.Document().CursorPosition())
+	}
+}
+
+func TestViMotionAndOperator(t *testing.T) {
+	SharedKillRing().Clear()
+	defer SharedKillRing().Clear()
+
+	for _, c := range []struct {
+		keys   string
+		text   string
+		cursor int
+	}{
+		// 移动
+		{"0w", "git commit -m fix(ui)", 4},
+		{"02w", "git commit -m fix(ui)", 11},
+		{"0W2W", "git commit -m fix(ui)", 14},
+		{"b", "git commit -m fix(ui)", 18},
+		{"e", "git commit -m fix(ui)", 20},
+		{"0e", "git commit -m fix(ui)", 2},
+		{"0$", "git commit -m fix(ui)", 20},
+		{"0f(", "git commit -m fix(ui)", 17},
+		{"0fm;", "git commit -m fix(ui)", 7},
+		{"Fi,", "git commit -m fix(ui)", 19},
+		{"02fi", "git commit -m fix(ui)", 8},
+		{"0fz", "git commit -m fix(ui)", 0},
+		// 操作符
+		{"0dw", "commit -m fix(ui)", 0},
+		{"0d2w", "-m fix(ui)", 0},
+		{"02dw", "-m fix(ui)", 0},
+		{"0cwpush\x1b", "push commit -m fix(ui)", 3},
+		{"0wcWamend\x1b", "git amend -m fix(ui)", 8},
+		{"0dt-", "-m fix(ui)", 0},
+		{"0df ", "commit -m fix(ui)", 0},
+		{"d0", ")", 0},
+		{"0wD", "git ", 3},
+		{"dd", "", 0},
+		{"ccstatus\x1b", "status", 5},
+		{"0x", "it commit -m fix(ui)", 0},
+		{"03x", " commit -m fix(ui)", 0},
+		{"X", "git commit -m fix(u)", 19},
+		{"0rG", "Git commit -m fix(ui)", 0},
+		{"02~", "GIt commit -m fix(ui)", 2},
+		{"0Iecho \x1b", "echo git commit -m fix(ui)", 4},
+		{"0A!\x1b", "git commit -m fix(ui)!", 21},
+		{"0ywP", "git git commit -m fix(ui)", 3},
+		{"0yep", "ggitit commit -m fix(ui)", 3},
+	} {
+		v := newViTester()
+		v.typeKeys("git commit -m fix(ui)")
+		v.press(input.Escape)
+		v.typeKeys(c.keys)
+		t.Run(c.keys, func(t *testing.T) {
+			v.check(t, c.text, c.cursor)
+		})
+	}
+}
+
+func TestViModes(t *testing.T) {
+	v := newViTester()
+	if v.c.Mode() != ViInsert || v.c.Indicator.Words[0] != ViModeWords[ViInsert] {
+		t.Fatalf("new line should be in insert mode")
+	}
+	v.typeKeys("hello")
+	v.press(input.Escape)
+	v.check(t, "hello", 4)
+	if v.c.Mode() != ViNormal || v.c.Indicator.Words[0] != ViModeWords[ViNormal] {
+		t.Fatalf("esc should switch to normal mode")
+	}
+	// 普通模式下字符不插入
+	v.typeKeys("hh")
+	v.check(t, "hello", 2)
+
+	// Esc 与后续按键解析为 Meta 键
+	v.typeKeys("a")
+	v.press(input.MetaB)
+	v.check(t, "hello", 0)
+
+	// 可视模式
+	v.typeKeys("vl")
+	if v.c.Mode() != ViVisual {
+		t.Fatalf("v should switch to visual mode")
+	}
+	if from, to := v.c.selection(); from != 0 || to != 2 {
+		t.Errorf("selection should be [0,2), but got [%d,%d)", from, to)
+	}
+	v.typeKeys("o")
+	v.check(t, "hello", 0)
+	v.typeKeys("d")
+	v.check(t, "llo", 0)
+	if v.c.Mode() != ViNormal {
+		t.Errorf("visual delete should back to normal mode")
+	}
+	v.typeKeys("v$cy\x1b")
+	v.check(t, "y", 0)
+
+	v.c.ResetBuffer()
+	if v.c.Mode() != ViInsert {
+		t.Errorf("reset buffer should back to insert mode")
+	}
+}
+
+func TestViRepeatAndUndo(t *testing.T) {
+	SharedKillRing().Clear()
+	defer SharedKillRing().Clear()
+
+	v := newViTester()
+	v.typeKeys("a b c d e f")
+	v.press(input.Escape)
+	v.typeKeys("0dw.")
+	v.check(t, "c d e f", 0)
+	v.typeKeys("2.")
+	v.check(t, "e f", 0)
+
+	// 重复修改命令及插入的文本
+	v.typeKeys("cwx\x1bw.")
+	v.check(t, "x x", 2)
+
+	// 修改命令整体撤销
+	v.typeKeys("u")
+	v.check(t, "x f", 2)
+	v.typeKeys("u")
+	v.check(t, "e f", 0)
+	v.press(input.ControlR)
+	v.check(t, "x f", 2)
+	v.c.SetRedoKey(input.MetaShiftZ)
+	v.typeKeys("2u")
+	v.check(t, "c d e f", 0)
+	v.press(input.MetaShiftZ)
+	v.check(t, "e f", 0)
+}
@@ -5,6 +5,8 @@
 package blocks
 
 import (
+	"time"
+
 	"github.com/aggronmagi/promptx/v2/input"
 	"github.com/aggronmagi/promptx/v2/output"
 )
@@ -29,6 +31,8 @@ type BlocksOptions struct {
 	Stderr output.ConsoleWriter
 	// Context
 	Context Context
+	// time to wait the canceled task to return, see RunTask
+	TaskGracePeriod time.Duration
 }
 
 // default global input options
@@ -102,6 +106,15 @@ func WithContext(v Context) BlocksOption {
 	}
 }
 
+// time to wait the canceled task to return, see RunTask
+func WithTaskGracePeriod(v time.Duration) BlocksOption {
+	return func(cc *BlocksOptions) BlocksOption {
+		previous := cc.TaskGracePeriod
+		cc.TaskGracePeriod = v
+		return WithTaskGracePeriod(previous)
+	}
+}
+
 // SetOption modify options
 func (cc *BlocksOptions) SetOption(opt BlocksOption) {
 	_ = opt(cc)
@@ -144,14 +157,15 @@ var watchDogBlocksOptions func(cc *BlocksOptions)
 // newDefaultBlocksOptions new option with default value
 func newDefaultBlocksOptions() *BlocksOptions {
 	cc := &BlocksOptions{
-		Inputs:  nil,
-		Selects: nil,
-		Common:  nil,
-		Manager: nil,
-		Input:   input.NewStandardInputParser(),
-		Output:  output.NewStandardOutputWriter(),
-		Stderr:  output.NewStderrWriter(),
-		Context: nil,
+		Inputs:          nil,
+		Selects:         nil,
+		Common:          nil,
+		Manager:         nil,
+		Input:           input.NewStandardInputParser(),
+		Output:          output.NewStandardOutputWriter(),
+		Stderr:          output.NewStderrWriter(),
+		Context:         nil,
+		TaskGracePeriod: time.Second,
 	}
 	return cc
 }
@@ -26,9 +26,15 @@ type CommonOptions struct {
 	ValidColor output.Color
 	ValidBG    output.Color
 	// exec input command
-	Exec     func(ctx Context, command string)
-	Finish   input.Key
-	Cancel   input.Key
+	Exec   func(ctx Context, command string)
+	Finish input.Key
+	Cancel input.Key
+	// redo the edit undone by ctrl + _
+	Redo input.Key
+	// key bindings of input line editor
+	EditMode EditMode
+	// key bindings of named editor actions, replace the default bindings
+	Binds    []ActionBind
 	Complete []CompleteOption
 	// history file
 	History string
@@ -40,6 +46,8 @@ type CommonOptions struct {
 	HistoryDedup bool
 	// record timestamps
 	HistoryTimestamp bool
+	// incremental history search (ctrl + r) matches characters in order, otherwise substring
+	HistorySearchFuzzy bool
 }
 
 func WithCommonOptionTip(v string) CommonOption {
@@ -140,6 +148,33 @@ func WithCommonOptionCancel(v input.Key) CommonOption {
 	}
 }
 
+// redo the edit undone by ctrl + _
+func WithCommonOptionRedo(v input.Key) CommonOption {
+	return func(cc *CommonOptions) CommonOption {
+		previous := cc.Redo
+		cc.Redo = v
+		return WithCommonOptionRedo(previous)
+	}
+}
+
+// key bindings of input line editor
+func WithCommonOptionEditMode(v EditMode) CommonOption {
+	return func(cc *CommonOptions) CommonOption {
+		previous := cc.EditMode
+		cc.EditMode = v
+		return WithCommonOptionEditMode(previous)
+	}
+}
+
+// key bindings of named editor actions, replace the default bindings
+func WithCommonOptionBinds(v ...ActionBind) CommonOption {
+	return func(cc *CommonOptions) CommonOption {
+		previous := cc.Binds
+		cc.Binds = v
+		return WithCommonOptionBinds(previous...)
+	}
+}
+
 func WithCommonOptionComplete(v ...CompleteOption) CommonOption {
 	return func(cc *CommonOptions) CommonOption {
 		previous := cc.Complete
@@ -193,6 +228,15 @@ func WithCommonOptionHistoryTimestamp(v bool) CommonOption {
 	}
 }
 
+// incremental history search (ctrl + r) matches characters in order, otherwise substring
+func WithCommonOptionHistorySearchFuzzy(v bool) CommonOption {
+	return func(cc *CommonOptions) CommonOption {
+		previous := cc.HistorySearchFuzzy
+		cc.HistorySearchFuzzy = v
+		return WithCommonOptionHistorySearchFuzzy(previous)
+	}
+}
+
 // SetOption modify options
 func (cc *CommonOptions) SetOption(opt CommonOption) {
 	_ = opt(cc)
@@ -235,24 +279,28 @@ var watchDogCommonOptions func(cc *CommonOptions)
 // newDefaultCommonOptions new option with default value
 func newDefaultCommonOptions() *CommonOptions {
 	cc := &CommonOptions{
-		Tip:               "",
-		TipColor:          output.Yellow,
-		TipBG:             output.DefaultColor,
-		Prefix:            ">>> ",
-		PrefixColor:       output.Green,
-		PrefixBG:          output.DefaultColor,
-		Valid:             nil,
-		ValidColor:        output.Red,
-		ValidBG:           output.DefaultColor,
-		Exec:              nil,
-		Finish:            input.Enter,
-		Cancel:            input.ControlC,
-		Complete:          nil,
-		History:           "",
-		HistoryMaxSize:    10000,
-		HistoryIgnoreDups: true,
-		HistoryDedup:      false,
-		HistoryTimestamp:  false,
+		Tip:                "",
+		TipColor:           output.Yellow,
+		TipBG:              output.DefaultColor,
+		Prefix:             ">>> ",
+		PrefixColor:        output.Green,
+		PrefixBG:           output.DefaultColor,
+		Valid:              nil,
+		ValidColor:         output.Red,
+		ValidBG:            output.DefaultColor,
+		Exec:               nil,
+		Finish:             input.Enter,
+		Cancel:             input.ControlC,
+		Redo:               input.MetaShiftZ,
+		EditMode:           EditModeEmacs,
+		Binds:              nil,
+		Complete:           nil,
+		History:            "",
+		HistoryMaxSize:     10000,
+		HistoryIgnoreDups:  true,
+		HistoryDedup:       false,
+		HistoryTimestamp:   false,
+		HistorySearchFuzzy: false,
 	}
 	return cc
 }
@@ -27,6 +27,12 @@ type InputOptions struct {
 	OnFinish    func(input string, eof error)
 	Finish      input.Key
 	Cancel      input.Key
+	// redo the edit undone by ctrl + _
+	Redo input.Key
+	// key bindings of input line editor
+	EditMode EditMode
+	// key bindings of named editor actions, replace the default bindings
+	Binds []ActionBind
 	// result display
 	ResultText   InputFinishTextFunc
 	ResultColor  output.Color
@@ -132,6 +138,33 @@ func WithInputOptionCancel(v input.Key) InputOption {
 	}
 }
 
+// redo the edit undone by ctrl + _
+func WithInputOptionRedo(v input.Key) InputOption {
+	return func(cc *InputOptions) InputOption {
+		previous := cc.Redo
+		cc.Redo = v
+		return WithInputOptionRedo(previous)
+	}
+}
+
+// key bindings of input line editor
+func WithInputOptionEditMode(v EditMode) InputOption {
+	return func(cc *InputOptions) InputOption {
+		previous := cc.EditMode
+		cc.EditMode = v
+		return WithInputOptionEditMode(previous)
+	}
+}
+
+// key bindings of named editor actions, replace the default bindings
+func WithInputOptionBinds(v ...ActionBind) InputOption {
+	return func(cc *InputOptions) InputOption {
+		previous := cc.Binds
+		cc.Binds = v
+		return WithInputOptionBinds(previous...)
+	}
+}
+
 // result display
 func WithInputOptionResultText(v InputFinishTextFunc) InputOption {
 	return func(cc *InputOptions) InputOption {
@@ -235,6 +268,9 @@ func newDefaultInputOptions() *InputOptions {
 		OnFinish:     nil,
 		Finish:       input.Enter,
 		Cancel:       input.ControlC,
+		Redo:         input.MetaShiftZ,
+		EditMode:     EditModeEmacs,
+		Binds:        nil,
 		ResultText:   defaultInputFinishText,
 		ResultColor:  output.Blue,
 		ResultBG:     output.DefaultColor,
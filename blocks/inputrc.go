@@ -0,0 +1,333 @@
+package blocks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+var (
+	// ErrUnknownAction the action name is not registered
+	ErrUnknownAction = errors.New("unknown action")
+	// ErrUnsupportedKey the key sequence or key name is not supported
+	ErrUnsupportedKey = errors.New("unsupported key")
+)
+
+// Inputrc key bindings and variables of a readline inputrc-style file.
+//
+//	# comment
+//	set editing-mode vi
+//	"\C-a": end-of-line
+//	Control-e: beginning-of-line
+//	"\e[A": history-search-backward
+//	Meta-g: "git status"
+//	$if mode=emacs
+//	"\C-t": transpose-chars
+//	$endif
+//
+// Quoted key sequences support \C- \M- \e and the common backslash escapes,
+// key names support Control- Meta- prefixes with a character or
+// Rubout DEL Escape ESC Tab Return RET Newline LFD Space SPC.
+// A quoted value is a macro inserting the text.
+// $if supports mode=emacs|vi and the application name "promptx", $include is ignored.
+// mode= tests the active edit mode, which is changed by "set editing-mode".
+// Like readline, the lines with unknown actions or unsupported keys are skipped.
+type Inputrc struct {
+	Binds []ActionBind
+	// Vars variables set by "set name value", names are lower case.
+	Vars map[string]string
+	// Warnings skipped lines, the errors wrap ErrUnknownAction or ErrUnsupportedKey.
+	Warnings []error
+	// active edit mode
+	mode EditMode
+}
+
+// EditMode edit mode set by "set editing-mode", ok is false if not set.
+func (rc *Inputrc) EditMode() (mode EditMode, ok bool) {
+	switch rc.Vars["editing-mode"] {
+	case "vi":
+		return EditModeVi, true
+	case "emacs":
+		return EditModeEmacs, true
+	}
+	return EditModeEmacs, false
+}
+
+// LoadInputrc load inputrc file, mode is the active edit mode.
+func LoadInputrc(file string, mode EditMode) (rc *Inputrc, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseInputrc(f, mode)
+}
+
+// ParseInputrc parse inputrc content, mode is the active edit mode.
+// the lines with unknown actions or unsupported keys are skipped and saved in Warnings,
+// syntax errors and read errors are returned.
+func ParseInputrc(r io.Reader, mode EditMode) (rc *Inputrc, err error) {
+	rc = &Inputrc{Vars: map[string]string{}, mode: mode}
+	// conditions of $if, true means the lines are used
+	var conds []bool
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if line[0] == '$' {
+			if conds, err = rc.directive(line, conds); err != nil {
+				return nil, fmt.Errorf("inputrc line %d: %w", num, err)
+			}
+			continue
+		}
+		if len(conds) > 0 && !conds[len(conds)-1] {
+			continue
+		}
+		err = rc.parseLine(line)
+		switch {
+		case errors.Is(err, ErrUnknownAction) || errors.Is(err, ErrUnsupportedKey):
+			rc.Warnings = append(rc.Warnings, fmt.Errorf("inputrc line %d: %w", num, err))
+		case err != nil:
+			return nil, fmt.Errorf("inputrc line %d: %w", num, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(conds) > 0 {
+		return nil, fmt.Errorf("inputrc: $if without $endif")
+	}
+	return rc, nil
+}
+
+func (rc *Inputrc) directive(line string, conds []bool) ([]bool, error) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	// lines of outer false condition are not used
+	parent := len(conds) == 0 || conds[len(conds)-1]
+	switch name {
+	case "$if":
+		return append(conds, parent && rc.test(arg)), nil
+	case "$else":
+		if len(conds) == 0 {
+			return nil, fmt.Errorf("$else without $if")
+		}
+		outer := len(conds) == 1 || conds[len(conds)-2]
+		conds[len(conds)-1] = outer && !conds[len(conds)-1]
+		return conds, nil
+	case "$endif":
+		if len(conds) == 0 {
+			return nil, fmt.Errorf("$endif without $if")
+		}
+		return conds[:len(conds)-1], nil
+	case "$include":
+		return conds, nil
+	}
+	return nil, fmt.Errorf("unknown directive %q", name)
+}
+
+// test condition of $if
+func (rc *Inputrc) test(cond string) bool {
+	if mode, ok := strings.CutPrefix(cond, "mode="); ok {
+		return rc.mode.String() == mode
+	}
+	if strings.Contains(cond, "=") {
+		// term= and variable tests
+		return false
+	}
+	return strings.EqualFold(cond, "promptx")
+}
+
+func (rc *Inputrc) parseLine(line string) (err error) {
+	if rest, ok := strings.CutPrefix(line, "set "); ok {
+		name, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		rc.Vars[strings.ToLower(name)] = strings.ToLower(strings.TrimSpace(value))
+		if mode, ok := rc.EditMode(); ok {
+			rc.mode = mode
+		}
+		return nil
+	}
+	var seq []byte
+	var value string
+	if line[0] == '"' {
+		end := quoteEnd(line)
+		if end < 0 {
+			return fmt.Errorf("unterminated key sequence %s", line)
+		}
+		if seq, err = unescapeKeySeq(line[1:end]); err != nil {
+			return err
+		}
+		value = line[end+1:]
+	} else {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("invalid key binding %q", line)
+		}
+		if seq, err = parseKeyName(strings.TrimSpace(name)); err != nil {
+			return err
+		}
+		value = rest
+	}
+	value, ok := strings.CutPrefix(strings.TrimSpace(value), ":")
+	if !ok && line[0] == '"' {
+		return fmt.Errorf("missing ':' in %q", line)
+	}
+	value = strings.TrimSpace(value)
+	key := input.GetKey(seq)
+	if key == input.NotDefined || key == input.Ignore {
+		return fmt.Errorf("%w %q", ErrUnsupportedKey, seq)
+	}
+	bind := ActionBind{Key: key}
+	switch {
+	case len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]:
+		macro, err := unescapeKeySeq(value[1 : len(value)-1])
+		if err != nil {
+			return err
+		}
+		bind.Macro = string(macro)
+	case HasAction(value):
+		bind.Action = value
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownAction, value)
+	}
+	rc.Binds = append(rc.Binds, bind)
+	return nil
+}
+
+// quoteEnd index of the closing quote of the string starts with '"'
+func quoteEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+var keySeqEscapes = map[byte]byte{
+	'a': '\a', 'b': '\b', 'd': 0x7f, 'e': 0x1b, 'f': '\f', 'n': '\n', 'r': '\r',
+	't': '\t', 'v': '\v', '\\': '\\', '"': '"', '\'': '\'',
+}
+
+// unescapeKeySeq unescape quoted key sequence
+func unescapeKeySeq(s string) (seq []byte, err error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			seq = append(seq, s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return nil, fmt.Errorf("invalid escape at end of %q", s)
+		}
+		switch {
+		case strings.HasPrefix(s[i:], "C-") && i+2 < len(s):
+			// \C-\M-x is the same as \M-\C-x
+			if strings.HasPrefix(s[i+2:], `\M-`) && i+5 < len(s) {
+				seq = append(seq, 0x1b, controlChar(s[i+5]))
+				i += 5
+				continue
+			}
+			seq = append(seq, controlChar(s[i+2]))
+			i += 2
+		case strings.HasPrefix(s[i:], "M-") && i+2 < len(s):
+			seq = append(seq, 0x1b)
+			// let the next loop deal \C-
+			if s[i+2] == '\\' {
+				i++
+				continue
+			}
+			seq = append(seq, s[i+2])
+			i += 2
+		case s[i] == 'x':
+			j := i + 1
+			for j < len(s) && j < i+3 && strings.IndexByte("0123456789abcdefABCDEF", s[j]) >= 0 {
+				j++
+			}
+			v, err := strconv.ParseUint(s[i+1:j], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hex escape in %q", s)
+			}
+			seq = append(seq, byte(v))
+			i = j - 1
+		case s[i] >= '0' && s[i] <= '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			v, err := strconv.ParseUint(s[i:j], 8, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid octal escape in %q", s)
+			}
+			seq = append(seq, byte(v))
+			i = j - 1
+		default:
+			if v, ok := keySeqEscapes[s[i]]; ok {
+				seq = append(seq, v)
+			} else {
+				seq = append(seq, s[i])
+			}
+		}
+	}
+	return seq, nil
+}
+
+func controlChar(c byte) byte {
+	if c == '?' {
+		return 0x7f
+	}
+	return c & 0x1f
+}
+
+var keyNames = map[string]byte{
+	"rubout": 0x7f, "del": 0x7f, "escape": 0x1b, "esc": 0x1b,
+	"tab": '\t', "return": '\r', "ret": '\r', "newline": '\n', "lfd": '\n',
+	"space": ' ', "spc": ' ',
+}
+
+// parseKeyName parse key name like Control-a Meta-Rubout
+func parseKeyName(name string) (seq []byte, err error) {
+	control, meta := false, false
+prefix:
+	for {
+		lower := strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(lower, "control-"):
+			control, name = true, name[len("control-"):]
+		case strings.HasPrefix(lower, "c-"):
+			control, name = true, name[len("c-"):]
+		case strings.HasPrefix(lower, "meta-"):
+			meta, name = true, name[len("meta-"):]
+		case strings.HasPrefix(lower, "m-"):
+			meta, name = true, name[len("m-"):]
+		default:
+			break prefix
+		}
+	}
+	c, ok := keyNames[strings.ToLower(name)]
+	switch {
+	case ok:
+	case len(name) == 1:
+		c = name[0]
+	default:
+		return nil, fmt.Errorf("%w name %q", ErrUnsupportedKey, name)
+	}
+	if control {
+		c = controlChar(c)
+	}
+	if meta {
+		seq = append(seq, 0x1b)
+	}
+	return append(seq, c), nil
+}
@@ -0,0 +1,140 @@
+package blocks
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+func TestParseInputrc(t *testing.T) {
+	rc, err := ParseInputrc(strings.NewReader(`
+# 注释和空行忽略
+set editing-mode vi
+set bell-style none
+
+"\C-a": end-of-line
+Control-e: beginning-of-line
+"\e[A": history-search-backward
+"\M-d": kill-word
+M-G: "git status"
+"\C-?": backward-delete-char
+$if mode=vi
+"\C-t": transpose-chars
+$else
+"\C-t": yank
+$endif
+$if Bash
+"\C-k": undo
+$endif
+$if promptx
+"\C-x": 'exit\n'
+$endif
+`), EditModeEmacs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode, ok := rc.EditMode(); !ok || mode != EditModeVi {
+		t.Errorf("edit mode should be vi, but got %v %v", mode, ok)
+	}
+	if rc.Vars["bell-style"] != "none" {
+		t.Errorf("vars should be set, but got %v", rc.Vars)
+	}
+	expected := []ActionBind{
+		{Key: input.ControlA, Action: "end-of-line"},
+		{Key: input.ControlE, Action: "beginning-of-line"},
+		{Key: input.Up, Action: ActionHistorySearchBackward},
+		{Key: input.MetaD, Action: "kill-word"},
+		{Key: input.MetaShiftG, Macro: "git status"},
+		{Key: input.Backspace, Action: "backward-delete-char"},
+		{Key: input.ControlT, Action: "transpose-chars"},
+		{Key: input.ControlX, Macro: "exit\n"},
+	}
+	if !reflect.DeepEqual(rc.Binds, expected) {
+		t.Errorf("binds should be %v, but got %v", expected, rc.Binds)
+	}
+
+	for _, c := range []string{
+		`"\C-a" end-of-line`,
+		`"\C-a: end-of-line`,
+		"$if mode=vi\n\"\\C-a\": undo",
+		"$endif",
+	} {
+		if _, err := ParseInputrc(strings.NewReader(c), EditModeEmacs); err == nil {
+			t.Errorf("%q should be invalid", c)
+		}
+	}
+}
+
+func TestParseInputrcSkipLines(t *testing.T) {
+	// 与 readline 一致, 未知的动作以及不支持的按键跳过, 其它绑定仍然生效
+	rc, err := ParseInputrc(strings.NewReader(`
+"\C-x\C-r": re-read-init-file
+TAB: menu-complete
+"\C-a": no-such-action
+"a": end-of-line
+Hyper-a: end-of-line
+"\C-e": end-of-line
+`), EditModeEmacs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ActionBind{
+		{Key: input.Tab, Action: ActionMenuComplete},
+		{Key: input.ControlE, Action: "end-of-line"},
+	}
+	if !reflect.DeepEqual(rc.Binds, expected) {
+		t.Errorf("binds should be %v, but got %v", expected, rc.Binds)
+	}
+	if len(rc.Warnings) != 4 {
+		t.Fatalf("should have 4 warnings, but got %v", rc.Warnings)
+	}
+	for k, err := range []error{ErrUnsupportedKey, ErrUnknownAction, ErrUnsupportedKey, ErrUnsupportedKey} {
+		if !errors.Is(rc.Warnings[k], err) {
+			t.Errorf("warning %d should be %v, but got %v", k, err, rc.Warnings[k])
+		}
+	}
+	if !strings.HasPrefix(rc.Warnings[0].Error(), "inputrc line 2: ") {
+		t.Errorf("warning should contain line number, but got %v", rc.Warnings[0])
+	}
+}
+
+func TestParseInputrcActiveMode(t *testing.T) {
+	content := `
+$if mode=vi
+"\C-t": transpose-chars
+$else
+"\C-t": yank
+$endif
+`
+	var scenarioTable = []struct {
+		mode     EditMode
+		expected string
+	}{
+		{mode: EditModeVi, expected: "transpose-chars"},
+		{mode: EditModeEmacs, expected: "yank"},
+	}
+	for _, s := range scenarioTable {
+		// 文件中没有 set editing-mode 时使用传入的编辑模式
+		rc, err := ParseInputrc(strings.NewReader(content), s.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rc.Binds) != 1 || rc.Binds[0].Action != s.expected {
+			t.Errorf("%v: should bind %s, but got %v", s.mode, s.expected, rc.Binds)
+		}
+		if _, ok := rc.EditMode(); ok {
+			t.Errorf("%v: edit mode should not be set", s.mode)
+		}
+	}
+	// set editing-mode 之后的条件使用新的编辑模式
+	rc, err := ParseInputrc(strings.NewReader("set editing-mode emacs\n"+content), EditModeVi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.Binds) != 1 || rc.Binds[0].Action != "yank" {
+		t.Errorf("should bind yank, but got %v", rc.Binds)
+	}
+}
@@ -0,0 +1,186 @@
+package blocks
+
+import (
+	"sync"
+
+	buffer "github.com/aggronmagi/promptx/v2/buffer"
+)
+
+// DefaultKillRingSize default max number of kill ring items.
+const DefaultKillRingSize = 60
+
+// KillRing stores the text killed by Ctrl-W/Ctrl-K/Ctrl-U.
+// Ctrl-Y inserts the latest kill, Meta-Y right after Ctrl-Y replaces the yanked text with the older one.
+// Consecutive kills are merged into one item.
+type KillRing struct {
+	mu    sync.Mutex
+	items []string
+	size  int
+	// index of the last yanked item
+	yankIndex int
+	// buffer state after the last kill or yank
+	last killRingState
+}
+
+// killRingAction last action of the kill ring
+type killRingAction int
+
+const (
+	killRingNone killRingAction = iota
+	killRingKill
+	killRingYank
+)
+
+// killRingState buffer state after kill or yank. it is used to check whether
+// the next kill/yank-pop follows immediately.
+type killRingState struct {
+	action killRingAction
+	buf    *buffer.Buffer
+	text   string
+	cursor int
+	// rune count of the yanked text
+	yanked int
+}
+
+var sharedKillRing = NewKillRing(DefaultKillRingSize)
+
+// SharedKillRing returns the kill ring shared by all input blocks.
+func SharedKillRing() *KillRing {
+	return sharedKillRing
+}
+
+// NewKillRing create kill ring. size <= 0 use DefaultKillRingSize.
+func NewKillRing(size int) *KillRing {
+	if size <= 0 {
+		size = DefaultKillRingSize
+	}
+	return &KillRing{size: size}
+}
+
+// Push add text as the latest item. it is never merged with the previous kill.
+func (r *KillRing) Push(text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.push(text)
+	r.last = killRingState{}
+}
+
+// Items returns all items, the latest first.
+func (r *KillRing) Items() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	items := make([]string, 0, len(r.items))
+	for k := len(r.items) - 1; k >= 0; k-- {
+		items = append(items, r.items[k])
+	}
+	return items
+}
+
+// Latest returns the latest item, false if the ring is empty.
+func (r *KillRing) Latest() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.items) == 0 {
+		return "", false
+	}
+	return r.items[len(r.items)-1], true
+}
+
+// Clear remove all items.
+func (r *KillRing) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = nil
+	r.last = killRingState{}
+}
+
+func (r *KillRing) push(text string) {
+	if text == "" {
+		return
+	}
+	r.items = append(r.items, text)
+	if len(r.items) > r.size {
+		r.items = r.items[len(r.items)-r.size:]
+	}
+}
+
+// follows reports whether buf is unchanged since the last action.
+func (r *KillRing) follows(action killRingAction, buf *buffer.Buffer) bool {
+	return r.last.action == action && r.last.buf == buf &&
+		r.last.text == buf.Text() && r.last.cursor == buf.Document().CursorPosition()
+}
+
+// record save buf state after action.
+func (r *KillRing) record(action killRingAction, buf *buffer.Buffer, yanked int) {
+	r.last = killRingState{
+		action: action,
+		buf:    buf,
+		text:   buf.Text(),
+		cursor: buf.Document().CursorPosition(),
+		yanked: yanked,
+	}
+}
+
+// kill delete count runes before or after the cursor of buf and add the deleted text.
+// if the previous action is also a kill on buf, text is merged into the latest item.
+func (r *KillRing) kill(buf *buffer.Buffer, count int, before bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	merge := r.follows(killRingKill, buf)
+	var text string
+	if before {
+		text = buf.DeleteBeforeCursor(count)
+	} else {
+		text = buf.Delete(count)
+	}
+	if text == "" {
+		return
+	}
+	if n := len(r.items); n > 0 && merge {
+		if before {
+			r.items[n-1] = text + r.items[n-1]
+		} else {
+			r.items[n-1] += text
+		}
+	} else {
+		r.push(text)
+	}
+	r.record(killRingKill, buf, 0)
+}
+
+// yank insert the latest item at the cursor of buf.
+func (r *KillRing) yank(buf *buffer.Buffer) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.items) == 0 {
+		return false
+	}
+	r.yankIndex = len(r.items) - 1
+	text := r.items[r.yankIndex]
+	buf.BeginEditGroup()
+	buf.InsertText(text, false, true)
+	buf.EndEditGroup()
+	r.record(killRingYank, buf, len([]rune(text)))
+	return true
+}
+
+// yankPop replace the text just yanked with the previous item.
+// it does nothing if the last action on buf is not a yank.
+func (r *KillRing) yankPop(buf *buffer.Buffer) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.items) == 0 || !r.follows(killRingYank, buf) {
+		return false
+	}
+	r.yankIndex--
+	if r.yankIndex < 0 || r.yankIndex >= len(r.items) {
+		r.yankIndex = len(r.items) - 1
+	}
+	text := r.items[r.yankIndex]
+	buf.BeginEditGroup()
+	buf.DeleteBeforeCursor(r.last.yanked)
+	buf.InsertText(text, false, true)
+	buf.EndEditGroup()
+	r.record(killRingYank, buf, len([]rune(text)))
+	return true
+}
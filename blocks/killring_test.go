@@ -0,0 +1,85 @@
+package blocks
+
+import (
+	"reflect"
+	"testing"
+
+	buffer "github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+func pressEmacsKey(t *testing.T, buf *buffer.Buffer, key input.Key) {
+	t.Helper()
+	for _, v := range emacsKeyBindings {
+		if v.Key == key {
+			v.Fn(&pressContext{buf: buf, key: key})
+			return
+		}
+	}
+	t.Fatalf("key %v not bind", key)
+}
+
+func newKillRingBuffer(text string, cursor int) *buffer.Buffer {
+	buf := buffer.NewBuffer()
+	buf.InsertText(text, false, true)
+	buf.CursorLeft(len([]rune(text)) - cursor)
+	return buf
+}
+
+func TestKillRing(t *testing.T) {
+	ring := SharedKillRing()
+	ring.Clear()
+	defer ring.Clear()
+
+	// 连续的删除合并为一项
+	buf := newKillRingBuffer("deploy 服务 now", 13)
+	pressEmacsKey(t, buf, input.ControlW)
+	pressEmacsKey(t, buf, input.ControlW)
+	if buf.Text() != "deploy " {
+		t.Errorf("text should be %q, but got %q", "deploy ", buf.Text())
+	}
+	if items := ring.Items(); !reflect.DeepEqual(items, []string{"服务 now"}) {
+		t.Errorf("kills should be merged, but got %q", items)
+	}
+
+	// 移动光标之后不再合并
+	pressEmacsKey(t, buf, input.ControlA)
+	pressEmacsKey(t, buf, input.ControlK)
+	if items := ring.Items(); !reflect.DeepEqual(items, []string{"deploy ", "服务 now"}) {
+		t.Errorf("kills should not be merged after moving cursor, but got %q", items)
+	}
+
+	// Go 代码添加的内容
+	ring.Push("status")
+
+	pressEmacsKey(t, buf, input.ControlY)
+	if buf.Text() != "status" {
+		t.Errorf("yank should insert the latest kill, but got %q", buf.Text())
+	}
+	for _, expected := range []string{"deploy ", "服务 now", "status"} {
+		pressEmacsKey(t, buf, input.MetaY)
+		if buf.Text() != expected {
+			t.Errorf("yank-pop should be %q, but got %q", expected, buf.Text())
+		}
+	}
+
+	// 不是紧跟在 yank 之后时 yank-pop 不修改内容
+	pressEmacsKey(t, buf, input.ControlB)
+	pressEmacsKey(t, buf, input.MetaY)
+	if buf.Text() != "status" {
+		t.Errorf("yank-pop should do nothing after moving cursor, but got %q", buf.Text())
+	}
+	if latest, ok := ring.Latest(); !ok || latest != "status" {
+		t.Errorf("latest should be status, but got %q", latest)
+	}
+}
+
+func TestKillRingSize(t *testing.T) {
+	ring := NewKillRing(2)
+	for _, v := range []string{"a", "", "b", "c"} {
+		ring.Push(v)
+	}
+	if items := ring.Items(); !reflect.DeepEqual(items, []string{"c", "b"}) {
+		t.Errorf("items should be %q, but got %q", []string{"c", "b"}, items)
+	}
+}
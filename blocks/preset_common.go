@@ -28,9 +28,15 @@ func promptxCommonOptions() interface{} {
 		"ValidColor": output.Color(output.Red),
 		"ValidBG":    output.Color(output.DefaultColor),
 		// exec input command
-		"Exec":     (func(ctx Context, command string))(nil),
-		"Finish":   input.Key(input.Enter),
-		"Cancel":   input.Key(input.ControlC),
+		"Exec":   (func(ctx Context, command string))(nil),
+		"Finish": input.Key(input.Enter),
+		"Cancel": input.Key(input.ControlC),
+		// redo the edit undone by ctrl + _
+		"Redo": input.Key(input.MetaShiftZ),
+		// key bindings of input line editor
+		"EditMode": EditMode(EditModeEmacs),
+		// key bindings of named editor actions, replace the default bindings
+		"Binds":    []ActionBind(nil),
 		"Complete": []CompleteOption(nil),
 		// history file
 		"History": string(""),
@@ -42,20 +48,32 @@ func promptxCommonOptions() interface{} {
 		"HistoryDedup": bool(false),
 		// record timestamps
 		"HistoryTimestamp": bool(false),
+		// incremental history search (ctrl + r) matches characters in order, otherwise substring
+		"HistorySearchFuzzy": bool(false),
 	}
 }
 
 // CommonBlockManager default block manager.
 type CommonBlockManager struct {
 	*BlocksBaseManager
-	Tip        *BlocksWords
-	PreWords   *BlocksWords
-	Input      *BlocksEmacsBuffer
+	Tip      *BlocksWords
+	PreWords *BlocksWords
+	// vi mode indicator, empty in emacs mode
+	ModeIndicator *BlocksWords
+	// incremental history search prompt, draw instead of prefix when searching
+	Search     *BlocksHistorySearch
+	Input      EditorBlocks
 	Validate   *BlocksNewLine
 	Completion *BlocksCompletion
 	cc         *CommonOptions
 	history    *history.History
 	hf         string
+	// actions provided by manager
+	actions map[string]KeyBindFunc
+	// histories switched out by SwitchHistoryFile, key is history file
+	histories map[string]*history.History
+	// history switched during current key event
+	historySwitched bool
 }
 
 // NewDefaultBlockManger default blocks manager.
@@ -66,20 +84,20 @@ func NewDefaultBlockManger(opts ...CommonOption) (m *CommonBlockManager) {
 		BlocksBaseManager: &BlocksBaseManager{},
 		Tip:               &BlocksWords{},
 		PreWords:          &BlocksWords{},
-		Input:             &BlocksEmacsBuffer{},
+		ModeIndicator:     &BlocksWords{},
+		Search:            &BlocksHistorySearch{},
 		Validate:          &BlocksNewLine{},
 		Completion:        &BlocksCompletion{},
 		cc:                cc,
-		history: history.NewHistory(
-			history.WithMaxSize(cc.HistoryMaxSize),
-			history.WithIgnoreDups(cc.HistoryIgnoreDups),
-			history.WithDeduplicate(cc.HistoryDedup),
-			history.WithTimestamp(cc.HistoryTimestamp),
-		),
+		histories:         make(map[string]*history.History),
 	}
+	m.Input = NewEditorBlocks(cc.EditMode, m.ModeIndicator)
+	m.history = m.newHistory()
 
 	m.AddMirrorMode(m.Tip)
 	m.AddMirrorMode(m.PreWords)
+	m.AddMirrorMode(m.ModeIndicator)
+	m.AddMirrorMode(m.Search)
 	m.AddMirrorMode(m.Input)
 	m.AddMirrorMode(m.Validate)
 	m.AddMirrorMode(m.Completion)
@@ -90,26 +108,29 @@ func NewDefaultBlockManger(opts ...CommonOption) (m *CommonBlockManager) {
 	m.Tip.SetIsDraw(func(status int) (draw bool) {
 		return status == NormalStatus
 	})
-	m.Completion.BindKey(func(ctx PressContext) (exit bool) {
-		buf := ctx.GetBuffer()
-		if new, ok := m.history.Older(buf.Text()); ok {
-			buf.Reset()
-			buf.InsertText(new, false, true)
-			//m.Completion.Update(ctx.GetBuffer().Document())
-			m.Completion.resetCompletion(ctx)
-		}
-		return false
-	}, input.ControlP, input.Up)
-	m.Completion.BindKey(func(ctx PressContext) (exit bool) {
-		buf := ctx.GetBuffer()
-		if new, ok := m.history.Newer(buf.Text()); ok {
-			buf.Reset()
-			buf.InsertText(new, false, true)
-			//m.Completion.Update(ctx.GetBuffer().Document())
-			m.Completion.resetCompletion(ctx)
-		}
-		return false
-	}, input.ControlN, input.Down)
+	m.PreWords.SetIsDraw(func(status int) (draw bool) {
+		return !m.Search.Searching()
+	})
+	m.ModeIndicator.SetIsDraw(func(status int) (draw bool) {
+		return status == NormalStatus && !m.Search.Searching()
+	})
+	m.Completion.BindKey(m.olderHistory, input.ControlP, input.Up)
+	m.Completion.BindKey(m.newerHistory, input.ControlN, input.Down)
+	m.Search.BindSearchKey(m.reverseSearchHistory, true, input.ControlR)
+	m.Search.BindSearchKey(m.forwardSearchHistory, false, input.ControlS)
+	// the history is filtered by the text before, so history search is the same as history navigation.
+	// complete already steps through the suggestions, the same as menu-complete.
+	m.actions = map[string]KeyBindFunc{
+		ActionPreviousHistory:       m.olderHistory,
+		ActionNextHistory:           m.newerHistory,
+		ActionHistorySearchBackward: m.olderHistory,
+		ActionHistorySearchForward:  m.newerHistory,
+		ActionComplete:              m.Completion.tabCompletion,
+		ActionMenuComplete:          m.Completion.tabCompletion,
+		ActionMenuCompleteBackward:  m.Completion.previousCompletion,
+		ActionReverseSearchHistory:  m.reverseSearchHistory,
+		ActionForwardSearchHistory:  m.forwardSearchHistory,
+	}
 
 	m.SetBeforeEvent(m.BeforeEvent)
 	m.SetBehindEvent(m.BehindEvent)
@@ -119,6 +140,110 @@ func NewDefaultBlockManger(opts ...CommonOption) (m *CommonBlockManager) {
 	return
 }
 
+// olderHistory replace input with the previous history
+func (m *CommonBlockManager) olderHistory(ctx PressContext) (exit bool) {
+	buf := ctx.GetBuffer()
+	if new, ok := m.history.Older(buf.Text()); ok {
+		buf.Reset()
+		buf.InsertText(new, false, true)
+		//m.Completion.Update(ctx.GetBuffer().Document())
+		m.Completion.resetCompletion(ctx)
+	}
+	return false
+}
+
+// newerHistory replace input with the next history
+func (m *CommonBlockManager) newerHistory(ctx PressContext) (exit bool) {
+	buf := ctx.GetBuffer()
+	if new, ok := m.history.Newer(buf.Text()); ok {
+		buf.Reset()
+		buf.InsertText(new, false, true)
+		//m.Completion.Update(ctx.GetBuffer().Document())
+		m.Completion.resetCompletion(ctx)
+	}
+	return false
+}
+
+// reverseSearchHistory start incremental search of older history
+func (m *CommonBlockManager) reverseSearchHistory(ctx PressContext) (exit bool) {
+	m.startSearch(ctx, true)
+	return false
+}
+
+// forwardSearchHistory start incremental search of newer history
+func (m *CommonBlockManager) forwardSearchHistory(ctx PressContext) (exit bool) {
+	m.startSearch(ctx, false)
+	return false
+}
+
+func (m *CommonBlockManager) startSearch(ctx PressContext, backward bool) {
+	buf := ctx.GetBuffer()
+	if buf == nil || m.Search.Searching() {
+		return
+	}
+	// ctrl + r is redo in vi normal mode
+	if vi, ok := m.Input.(*BlocksViBuffer); ok && vi.Mode() != ViInsert {
+		return
+	}
+	m.Completion.resetCompletion(ctx)
+	m.Search.Start(buf, m.history.GetCommands(), backward)
+}
+
+// Event deal console key press, keys are processed by history search when searching.
+func (m *CommonBlockManager) Event(key input.Key, in []byte) (exit bool) {
+	buf := m.Input.GetBuffer()
+	if !m.Search.Searching() || buf == nil {
+		return m.BlocksBaseManager.Event(key, in)
+	}
+	handled := m.Search.HandleEvent(buf, key, in)
+	if !m.Search.Searching() {
+		// history navigation starts from the accepted line
+		m.history.Rebuild(buf.Text(), false)
+	}
+	if !handled {
+		return m.BlocksBaseManager.Event(key, in)
+	}
+	m.Render(NormalStatus)
+	return false
+}
+
+// bindActions bind the keys of Binds option, default bindings of the keys are replaced.
+func (m *CommonBlockManager) bindActions() {
+	for _, bind := range m.cc.Binds {
+		fn, isLocal, ok := actionFunc(bind, m.actions)
+		if !ok {
+			continue
+		}
+		m.Search.UnbindKey(bind.Key)
+		switch {
+		// search keys also step to the next match when searching.
+		case bind.Action == ActionReverseSearchHistory || bind.Action == ActionForwardSearchHistory:
+			m.Input.UnbindKey(bind.Key)
+			m.Completion.UnbindKey(bind.Key)
+			m.Search.BindSearchKey(fn, bind.Action == ActionReverseSearchHistory, bind.Key)
+			continue
+		// history and completion actions are bound to completion, the input text is not changed by editing.
+		case isLocal:
+			m.Input.UnbindKey(bind.Key)
+			m.Completion.BindKey(fn, bind.Key)
+			continue
+		}
+		m.Input.BindKey(fn, bind.Key)
+		m.Completion.BindKey(m.Completion.refreshCompletion, bind.Key)
+	}
+}
+
+// newHistory create history with current options
+func (m *CommonBlockManager) newHistory() *history.History {
+	cc := m.cc
+	return history.NewHistory(
+		history.WithMaxSize(cc.HistoryMaxSize),
+		history.WithIgnoreDups(cc.HistoryIgnoreDups),
+		history.WithDeduplicate(cc.HistoryDedup),
+		history.WithTimestamp(cc.HistoryTimestamp),
+	)
+}
+
 func (m *CommonBlockManager) applyOptionModify() {
 	cc := m.cc
 
@@ -158,11 +283,17 @@ func (m *CommonBlockManager) applyOptionModify() {
 		})
 	}
 
+	m.Search.TextColor = cc.PrefixColor
+	m.Search.BGColor = cc.PrefixBG
+	m.Search.Fuzzy = cc.HistorySearchFuzzy
+
 	m.Validate.TextColor = cc.ValidColor
 	m.Validate.BGColor = cc.ValidBG
 
 	m.SetCancelKey(cc.Cancel)
 	m.SetFinishKey(cc.Finish)
+	m.Input.SetRedoKey(cc.Redo)
+	m.bindActions()
 	// completion
 	if m.Completion.Cfg == nil {
 		m.Completion.Cfg = NewCompleteOptions(cc.Complete...)
@@ -242,6 +373,33 @@ func (m *CommonBlockManager) ResetHistoryFile(filename string) {
 	m.hf = cc.History
 }
 
+// SwitchHistoryFile save current history and switch to the history of filename.
+// the history of each file is kept in memory, switch back restores records and cursor.
+// empty filename means history only in memory.
+func (m *CommonBlockManager) SwitchHistoryFile(filename string) {
+	cc := m.cc
+	if filename == cc.History {
+		return
+	}
+	if len(m.hf) > 0 {
+		debug.AssertNoError(m.history.Save(m.hf))
+	}
+	m.histories[cc.History] = m.history
+
+	if h, ok := m.histories[filename]; ok {
+		delete(m.histories, filename)
+		m.history = h
+	} else {
+		m.history = m.newHistory()
+		if len(filename) > 0 {
+			debug.AssertNoError(m.history.Load(filename))
+		}
+	}
+	cc.History = filename
+	m.hf = filename
+	m.historySwitched = true
+}
+
 func (m *CommonBlockManager) SetOption(opt CommonOption) {
 	_ = opt(m.cc)
 	m.applyOptionModify()
@@ -257,20 +415,23 @@ func (m *CommonBlockManager) ApplyOption(opts ...CommonOption) {
 func (m *CommonBlockManager) BeforeEvent(ctx PressContext, key input.Key, in []byte) (exit bool) {
 	// first deal input char event
 	if key == input.NotDefined && ctx.GetBuffer() != nil {
-		ctx.GetBuffer().InsertText(string(in), false, true)
+		m.Input.InsertTyped(ctx.GetBuffer(), in)
 	}
 
 	return
 }
 
 func (m *CommonBlockManager) BehindEvent(ctx PressContext, key input.Key, in []byte) (exit bool) {
+	defer func() {
+		m.historySwitched = false
+	}()
 
 	if ctx.GetBuffer() != nil {
 		if m.Input.IsBind(key) || key == input.NotDefined {
 			m.history.Rebuild(ctx.GetBuffer().Text(), false)
 		}
-		if key == m.cc.Cancel ||
-			key == m.cc.Finish {
+		// keep cursor of the history restored by SwitchHistoryFile
+		if (key == m.cc.Cancel || key == m.cc.Finish) && !m.historySwitched {
 			m.history.Rebuild("", true)
 		}
 		// when exit,reset completion.
@@ -0,0 +1,166 @@
+package blocks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+func TestSwitchHistoryFile(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.history")
+	area := filepath.Join(dir, "area.history")
+	if err := os.WriteFile(area, []byte("play\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDefaultBlockManger(WithCommonOptionHistory(main))
+	m.AddHistory("login")
+	m.AddHistory("status")
+	if v, _ := m.history.Older("draft"); v != "status" {
+		t.Fatalf("older should be status, but got %q", v)
+	}
+
+	m.SwitchHistoryFile(area)
+	if data, _ := os.ReadFile(main); string(data) != "login\nstatus\n" {
+		t.Errorf("history should be saved when switch out, but got %q", data)
+	}
+	if v, ok := m.history.Older(""); !ok || v != "play" {
+		t.Errorf("history should be loaded from file, but got %q", v)
+	}
+	m.AddHistory("attack")
+
+	// 切换回来时恢复记录和光标位置
+	m.SwitchHistoryFile(main)
+	if v, ok := m.history.Older("status"); !ok || v != "login" {
+		t.Errorf("cursor should be restored, but got %q %v", v, ok)
+	}
+	if data, _ := os.ReadFile(area); string(data) != "play\nattack\n" {
+		t.Errorf("history should be saved when switch out, but got %q", data)
+	}
+
+	// 切换到相同的文件不修改历史记录
+	m.SwitchHistoryFile(main)
+	if v, ok := m.history.Newer("login"); !ok || v != "status" {
+		t.Errorf("switch to same file should keep history, but got %q %v", v, ok)
+	}
+}
+
+func TestBindActions(t *testing.T) {
+	m := NewDefaultBlockManger(WithCommonOptionBinds(
+		ActionBind{Key: input.ControlA, Action: "end-of-line"},
+		ActionBind{Key: input.ControlT, Action: ActionPreviousHistory},
+		ActionBind{Key: input.Up, Action: "beginning-of-line"},
+		ActionBind{Key: input.MetaG, Macro: "git "},
+	))
+	m.AddHistory("status")
+	// 历史记录按键由补全块处理
+	m.Completion.SetActive(true)
+	buf := m.Input.GetBuffer()
+	press := func(key input.Key) {
+		ctx := &pressContext{buf: buf, key: key}
+		m.Input.OnEvent(ctx, key, nil)
+		m.Completion.OnEvent(ctx, key, nil)
+	}
+	press(input.MetaG)
+	buf.InsertText("log", false, true)
+	press(input.Up)
+	if buf.Text() != "git log" || buf.Document().CursorPosition() != 0 {
+		t.Errorf("up should go to beginning of line, but got %q at %d", buf.Text(), buf.Document().CursorPosition())
+	}
+	press(input.ControlA)
+	if buf.Document().CursorPosition() != 7 {
+		t.Errorf("ctrl-a should go to end of line, but got %d", buf.Document().CursorPosition())
+	}
+	buf.Reset()
+	press(input.ControlT)
+	if buf.Text() != "status" {
+		t.Errorf("ctrl-t should be previous history, but got %q", buf.Text())
+	}
+	// 未绑定的按键保持默认功能
+	press(input.ControlE)
+	press(input.ControlW)
+	if buf.Text() != "" {
+		t.Errorf("default bindings should be kept, but got %q", buf.Text())
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	var executed string
+	m := NewDefaultBlockManger(WithCommonOptionExec(func(ctx Context, command string) {
+		executed = command
+	}))
+	for _, v := range []string{"git status", "make build", "git log", "go test"} {
+		m.AddHistory(v)
+	}
+	buf := m.Input.GetBuffer()
+	typed := func(text string) {
+		m.Event(input.NotDefined, []byte(text))
+	}
+	check := func(text string, cursor int, prompt string) {
+		t.Helper()
+		if buf.Text() != text || buf.Document().CursorPosition() != cursor {
+			t.Errorf("line should be %q at %d, but got %q at %d", text, cursor, buf.Text(), buf.Document().CursorPosition())
+		}
+		if m.Search.Searching() != (prompt != "") {
+			t.Errorf("searching should be %v", prompt != "")
+		}
+		if prompt != "" && m.Search.prompt() != prompt {
+			t.Errorf("prompt should be %q, but got %q", prompt, m.Search.prompt())
+		}
+	}
+
+	typed("draft")
+	m.Event(input.ControlR, nil)
+	check("draft", 5, "(reverse-i-search)'': ")
+	typed("g")
+	typed("it")
+	check("git log", 0, "(reverse-i-search)'git': ")
+	// 重复 ctrl-r 查找更早的记录
+	m.Event(input.ControlR, nil)
+	check("git status", 0, "(reverse-i-search)'git': ")
+	m.Event(input.ControlR, nil)
+	check("git status", 0, "(failed reverse-i-search)'git': ")
+	m.Event(input.ControlS, nil)
+	check("git log", 0, "(i-search)'git': ")
+	typed(" s")
+	check("git log", 0, "(failed i-search)'git s': ")
+	m.Event(input.Backspace, nil)
+	check("git log", 0, "(i-search)'git ': ")
+	// ctrl-g 恢复原来的输入
+	m.Event(input.ControlG, nil)
+	check("draft", 5, "")
+
+	// esc 接受匹配的记录继续编辑
+	m.Event(input.ControlR, nil)
+	typed("build")
+	m.Event(input.Escape, nil)
+	check("make build", 5, "")
+	// 没有输入时使用上次查找的字符串
+	m.Event(input.ControlR, nil)
+	m.Event(input.ControlR, nil)
+	check("make build", 5, "(reverse-i-search)'build': ")
+	// 其他按键接受匹配的记录后正常处理
+	m.Event(input.ControlE, nil)
+	check("make build", 10, "")
+
+	buf.Reset()
+	m.Event(input.ControlR, nil)
+	typed("test")
+	m.Event(input.Enter, nil)
+	// 输入完成后替换为新的 buffer
+	buf = m.Input.GetBuffer()
+	if executed != "go test" || buf.Text() != "" || m.Search.Searching() {
+		t.Errorf("enter should execute the match, but got %q", executed)
+	}
+
+	m.ApplyOption(WithCommonOptionHistorySearchFuzzy(true))
+	m.Event(input.ControlR, nil)
+	typed("gtu")
+	check("git status", 0, "(reverse-i-search)'gtu': ")
+	m.Event(input.ControlC, nil)
+	buf = m.Input.GetBuffer()
+	check("", 0, "")
+}
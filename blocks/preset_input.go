@@ -28,6 +28,12 @@ func promptxInputOptions() interface{} {
 		"OnFinish":    (func(input string, eof error))(nil),
 		"Finish":      input.Key(input.Enter),
 		"Cancel":      input.Key(input.ControlC),
+		// redo the edit undone by ctrl + _
+		"Redo": input.Key(input.MetaShiftZ),
+		// key bindings of input line editor
+		"EditMode": EditMode(EditModeEmacs),
+		// key bindings of named editor actions, replace the default bindings
+		"Binds": []ActionBind(nil),
 		// result display
 		"ResultText":   InputFinishTextFunc(defaultInputFinishText),
 		"ResultColor":  output.Color(output.Blue),
@@ -71,11 +77,13 @@ func defaultInputFinishText(cc *InputOptions, status int, doc *buffer.Document,
 
 type InputBlockManager struct {
 	*BlocksBaseManager
-	PreWords   *BlocksWords
-	Input      *BlocksEmacsBuffer
-	Validate   *BlocksNewLine
-	cc         *InputOptions
-	useDefault bool
+	PreWords *BlocksWords
+	// vi mode indicator, empty in emacs mode
+	ModeIndicator *BlocksWords
+	Input         EditorBlocks
+	Validate      *BlocksNewLine
+	cc            *InputOptions
+	useDefault    bool
 }
 
 // NewInputManager new input text
@@ -84,10 +92,11 @@ func NewInputManager(cc *InputOptions) (m *InputBlockManager) {
 	m = &InputBlockManager{
 		BlocksBaseManager: &BlocksBaseManager{},
 		PreWords:          &BlocksWords{},
-		Input:             &BlocksEmacsBuffer{},
+		ModeIndicator:     &BlocksWords{},
 		Validate:          &BlocksNewLine{},
 		cc:                cc,
 	}
+	m.Input = NewEditorBlocks(cc.EditMode, m.ModeIndicator)
 	if len(cc.Tip) > 0 {
 		m.PreWords.Words = append(m.PreWords.Words, &Word{
 			Text:      cc.Tip,
@@ -127,10 +136,17 @@ func NewInputManager(cc *InputOptions) (m *InputBlockManager) {
 
 	m.SetCancelKey(cc.Cancel)
 	m.SetFinishKey(cc.Finish)
+	m.Input.SetRedoKey(cc.Redo)
 
 	m.AddMirrorMode(m.PreWords)
+	m.AddMirrorMode(m.ModeIndicator)
 	m.AddMirrorMode(m.Input)
 	m.AddMirrorMode(m.Validate)
+	for _, bind := range cc.Binds {
+		if fn, _, ok := actionFunc(bind, nil); ok {
+			m.Input.BindKey(fn, bind.Key)
+		}
+	}
 
 	m.SetBeforeEvent(m.BeforeEvent)
 
@@ -202,6 +218,7 @@ func (m *InputBlockManager) PreCheckCallBack(status int, buf *buffer.Buffer) (su
 	if status == FinishStatus || status == CancelStatus {
 		// hide blocks
 		m.Input.SetActive(false)
+		m.ModeIndicator.SetActive(false)
 		m.Validate.SetActive(false)
 
 		m.PreWords.Words = m.cc.ResultText(m.cc, status, buf.Document(), m.cc.Default)
@@ -214,7 +231,7 @@ func (m *InputBlockManager) BeforeEvent(ctx PressContext, key input.Key, in []by
 	// first deal input char event
 	if key == input.NotDefined && ctx.GetBuffer() != nil {
 		m.useDefault = false
-		ctx.GetBuffer().InsertText(string(in), false, true)
+		m.Input.InsertTyped(ctx.GetBuffer(), in)
 	}
 	return
 }
@@ -14,6 +14,7 @@ type Buffer struct {
 	cursorPosition  int
 	cacheDocument   *Document
 	preferredColumn int // Remember the original column for the next up/down movement.
+	undo            undoHistory
 }
 
 // Text returns string of the current line.
@@ -50,6 +51,11 @@ func (b *Buffer) InsertText(v string, overwrite bool, moveCursor bool) {
 		}
 	}
 	v = string(nv)
+	kind := editOther
+	if len(nv) == 1 && nv[0] != '\n' && !overwrite && moveCursor {
+		kind = editTyping
+	}
+	defer b.recordEdit(kind)()
 	or := []rune(b.Text())
 	oc := b.cursorPosition
 
@@ -86,6 +92,11 @@ func (b *Buffer) setCursorPosition(p int) {
 	}
 }
 
+// SetCursorPosition move cursor to the position p, p is limited to the range of text.
+func (b *Buffer) SetCursorPosition(p int) {
+	b.setCursorPosition(min(p, len([]rune(b.Text()))))
+}
+
 func (b *Buffer) setDocument(d *Document) {
 	b.cacheDocument = d
 	b.setCursorPosition(d.cursorPosition) // Call before setText because setText check the relation between cursorPosition and line length.
@@ -133,6 +144,7 @@ func (b *Buffer) CursorDown(count int) {
 // DeleteBeforeCursor delete specified number of characters before cursor and return the deleted text.
 func (b *Buffer) DeleteBeforeCursor(count int) (deleted string) {
 	debug.Assert(count >= 0, "count should be positive")
+	defer b.recordEdit(editOther)()
 	r := []rune(b.Text())
 
 	if b.cursorPosition > 0 {
@@ -160,10 +172,12 @@ func (b *Buffer) NewLine(copyMargin bool) {
 
 // Delete specified number of characters and Return the deleted text.
 func (b *Buffer) Delete(count int) (deleted string) {
+	defer b.recordEdit(editOther)()
 	r := []rune(b.Text())
 	if b.cursorPosition < len(r) {
-		deleted = b.Document().TextAfterCursor()[:count]
-		b.setText(string(r[:b.cursorPosition]) + string(r[b.cursorPosition+len(deleted):]))
+		end := min(b.cursorPosition+count, len(r))
+		deleted = string(r[b.cursorPosition:end])
+		b.setText(string(r[:b.cursorPosition]) + string(r[end:]))
 	}
 	return
 }
@@ -171,6 +185,8 @@ func (b *Buffer) Delete(count int) (deleted string) {
 // JoinNextLine joins the next line to the current one by deleting the line ending after the current line.
 func (b *Buffer) JoinNextLine(separator string) {
 	if !b.Document().OnLastLine() {
+		b.BeginEditGroup()
+		defer b.EndEditGroup()
 		b.cursorPosition += b.Document().GetEndOfLinePosition()
 		b.Delete(1)
 		// Remove spaces
@@ -187,7 +203,9 @@ func (b *Buffer) SwapCharactersBeforeCursor() {
 	}
 }
 
+// Reset clear the text and the undo history.
 func (b *Buffer) Reset() {
+	b.undo = undoHistory{}
 	b.workingIndex = 0
 	b.workingLines = []string{""}
 	b.preferredColumn = -1
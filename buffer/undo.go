@@ -0,0 +1,135 @@
+package buffer
+
+import "unicode"
+
+// maxUndo max number of undo records kept by Buffer.
+const maxUndo = 100
+
+// editKind kind of the last edit, used to coalesce typing.
+type editKind int
+
+const (
+	editNone editKind = iota
+	// single character inserted by typing
+	editTyping
+	// other edits
+	editOther
+	// grouped edits
+	editGroup
+)
+
+// bufferState text and cursor position of buffer.
+type bufferState struct {
+	text   string
+	cursor int
+}
+
+// undoHistory undo/redo stacks of Buffer.
+type undoHistory struct {
+	undo []bufferState
+	redo []bufferState
+	// last edit and the state after it, to coalesce typing
+	lastKind  editKind
+	lastState bufferState
+	// grouped edits
+	groupDepth  int
+	groupBefore bufferState
+}
+
+func (b *Buffer) state() bufferState {
+	return bufferState{text: b.Text(), cursor: b.cursorPosition}
+}
+
+func (b *Buffer) restore(s bufferState) {
+	b.setDocument(&Document{Text: s.text, cursorPosition: s.cursor})
+}
+
+// recordEdit save the state before an edit, call the returned function after the edit.
+// edits that do not change the text are not recorded.
+func (b *Buffer) recordEdit(kind editKind) (done func()) {
+	if b.undo.groupDepth > 0 {
+		return func() {}
+	}
+	before := b.state()
+	return func() {
+		if b.Text() == before.text {
+			return
+		}
+		h := &b.undo
+		// typing a run of characters is undone as a whole word
+		coalesce := kind == editTyping && h.lastKind == editTyping && h.lastState == before &&
+			!startsWord([]rune(before.text), before.cursor, []rune(b.Text()))
+		if !coalesce {
+			h.push(before)
+		}
+		h.redo = nil
+		h.lastKind = kind
+		h.lastState = b.state()
+	}
+}
+
+// startsWord reports whether the character inserted at cursor starts a new word.
+func startsWord(before []rune, cursor int, after []rune) bool {
+	if cursor <= 0 || cursor > len(before) || cursor >= len(after) {
+		return false
+	}
+	return unicode.IsSpace(before[cursor-1]) && !unicode.IsSpace(after[cursor])
+}
+
+func (h *undoHistory) push(s bufferState) {
+	h.undo = append(h.undo, s)
+	if len(h.undo) > maxUndo {
+		h.undo = h.undo[len(h.undo)-maxUndo:]
+	}
+}
+
+// BeginEditGroup start grouped edits. edits until the matching EndEditGroup are undone at once.
+// groups can be nested, only the outermost group is recorded.
+func (b *Buffer) BeginEditGroup() {
+	if b.undo.groupDepth == 0 {
+		b.undo.groupBefore = b.state()
+	}
+	b.undo.groupDepth++
+}
+
+// EndEditGroup end grouped edits.
+func (b *Buffer) EndEditGroup() {
+	h := &b.undo
+	if h.groupDepth == 0 {
+		return
+	}
+	h.groupDepth--
+	if h.groupDepth > 0 || b.Text() == h.groupBefore.text {
+		return
+	}
+	h.push(h.groupBefore)
+	h.redo = nil
+	h.lastKind = editGroup
+	h.lastState = b.state()
+}
+
+// Undo restore the text before the last edit. returns false if nothing to undo.
+func (b *Buffer) Undo() bool {
+	h := &b.undo
+	if len(h.undo) == 0 {
+		return false
+	}
+	h.redo = append(h.redo, b.state())
+	b.restore(h.undo[len(h.undo)-1])
+	h.undo = h.undo[:len(h.undo)-1]
+	h.lastKind = editNone
+	return true
+}
+
+// Redo restore the text undone by Undo. returns false if nothing to redo.
+func (b *Buffer) Redo() bool {
+	h := &b.undo
+	if len(h.redo) == 0 {
+		return false
+	}
+	h.undo = append(h.undo, b.state())
+	b.restore(h.redo[len(h.redo)-1])
+	h.redo = h.redo[:len(h.redo)-1]
+	h.lastKind = editNone
+	return true
+}
@@ -0,0 +1,117 @@
+package buffer
+
+import "testing"
+
+func typeText(b *Buffer, text string) {
+	for _, r := range text {
+		b.InsertText(string(r), false, true)
+	}
+}
+
+func TestBuffer_UndoTyping(t *testing.T) {
+	b := NewBuffer()
+	typeText(b, "deploy now")
+
+	// 一次撤销删除一个单词
+	for _, expected := range []string{"deploy ", ""} {
+		if !b.Undo() {
+			t.Fatalf("Undo should succeed")
+		}
+		if b.Text() != expected {
+			t.Errorf("Text should be %#v, got %#v", expected, b.Text())
+		}
+	}
+	if b.Undo() {
+		t.Errorf("Undo should fail when nothing to undo")
+	}
+
+	for _, expected := range []string{"deploy ", "deploy now"} {
+		if !b.Redo() {
+			t.Fatalf("Redo should succeed")
+		}
+		if b.Text() != expected {
+			t.Errorf("Text should be %#v, got %#v", expected, b.Text())
+		}
+	}
+	if b.Redo() {
+		t.Errorf("Redo should fail when nothing to redo")
+	}
+}
+
+func TestBuffer_UndoEdits(t *testing.T) {
+	b := NewBuffer()
+	b.InsertText("hello world", false, true)
+	b.CursorLeft(6)
+	b.DeleteBeforeCursor(5)
+	// 移动光标之后的输入不与之前的输入合并
+	typeText(b, "hi")
+	b.Delete(1)
+	if b.Text() != "hiworld" {
+		t.Fatalf("Text should be %#v, got %#v", "hiworld", b.Text())
+	}
+	// 没有修改内容的操作不记录
+	b.Delete(0)
+
+	expected := []struct {
+		text   string
+		cursor int
+	}{
+		{"hi world", 2},
+		{" world", 0},
+		{"hello world", 5},
+		{"", 0},
+	}
+	for _, e := range expected {
+		b.Undo()
+		if b.Text() != e.text || b.Document().CursorPosition() != e.cursor {
+			t.Errorf("should be %#v at %d, got %#v at %d", e.text, e.cursor, b.Text(), b.Document().CursorPosition())
+		}
+	}
+
+	// 新的修改清空重做记录
+	b.Redo()
+	b.InsertText("!", false, true)
+	if b.Redo() {
+		t.Errorf("Redo should fail after new edit")
+	}
+}
+
+func TestBuffer_UndoGroup(t *testing.T) {
+	b := NewBuffer()
+	typeText(b, "git st")
+
+	// 补全: 删除当前单词并插入建议
+	b.BeginEditGroup()
+	b.DeleteBeforeCursor(2)
+	b.BeginEditGroup()
+	b.InsertText("status ", false, true)
+	b.EndEditGroup()
+	b.EndEditGroup()
+	if b.Text() != "git status " {
+		t.Fatalf("Text should be %#v, got %#v", "git status ", b.Text())
+	}
+
+	b.Undo()
+	if b.Text() != "git st" {
+		t.Errorf("grouped edits should be undone at once, got %#v", b.Text())
+	}
+
+	b.Reset()
+	if b.Undo() {
+		t.Errorf("Reset should clear undo history")
+	}
+}
+
+func TestBuffer_UndoJoinNextLine(t *testing.T) {
+	b := NewBuffer()
+	b.InsertText("line1\n  line2", false, true)
+	b.CursorUp(1)
+	b.JoinNextLine(" ")
+	if b.Text() != "line1 line2" {
+		t.Fatalf("Text should be %#v, got %#v", "line1 line2", b.Text())
+	}
+	b.Undo()
+	if b.Text() != "line1\n  line2" {
+		t.Errorf("JoinNextLine should be undone at once, got %#v", b.Text())
+	}
+}
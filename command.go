@@ -19,6 +19,17 @@ type Commander interface {
 	Exec(ctx blocks.Context)
 }
 
+// CommanderE 返回错误的自定义命令接口
+// 返回的错误会设置命令的执行状态, 并交给命令组的 OnCommandError 处理
+type CommanderE interface {
+	// Name 返回命令名称
+	Name() string
+	// Help 返回命令帮助信息
+	Help() string
+	// Exec 执行命令
+	Exec(ctx blocks.Context) error
+}
+
 type rootCommandConfig struct {
 	// 命令前缀
 	commandPrefix string
@@ -32,6 +43,10 @@ type rootCommandConfig struct {
 	history string
 	// 切换时的回调函数
 	onChange func(ctx blocks.Context, args ...interface{})
+	// 命令执行失败的处理函数
+	onCommandError func(ctx blocks.Context, line string, err error)
+	// 命令中间件
+	middlewares []Middleware
 }
 
 func newRootCommandConfig() *rootCommandConfig {
@@ -59,16 +74,18 @@ type Command struct {
 	// 子命令映射表（用于快速查找）
 	children map[string]*Command
 	// 命令执行函数
-	// 统一签名：func(ctx blocks.Context, arg any)
+	// 统一签名：func(ctx blocks.Context, arg any) error
 	// 对于泛型命令，arg 是解析后的 ARG 结构体指针
 	// 对于自定义命令，arg 是实现接口的结构体指针
-	execFunc func(ctx blocks.Context, arg any)
+	execFunc func(ctx blocks.Context, arg any) error
 	// 参数定义（用于泛型命令和自定义命令）
 	argDefs []*ArgDef
 	// 参数类型 新建参数结构体的反射类型, 保存解析命令行参数的值, 传递给execFunc.
 	argType reflect.Type
 	// 是否为 Commander 类型命令
 	isCommander bool
+	// 命令中间件
+	middlewares []Middleware
 }
 
 // NewCommandWithFunc 创建一个新的命令（泛型方式）
@@ -79,6 +96,20 @@ type Command struct {
 func NewCommandWithFunc[ARG any](
 	name, help string,
 	run func(ctx blocks.Context, arg *ARG),
+) *Command {
+	return NewCommandWithFuncE(name, help, func(ctx blocks.Context, arg *ARG) error {
+		run(ctx, arg)
+		return nil
+	})
+}
+
+// NewCommandWithFuncE 创建一个新的命令（泛型方式），命令执行函数返回错误
+// name: 命令名称
+// help: 命令帮助信息
+// run: 命令执行函数, 返回的错误会设置命令的执行状态
+func NewCommandWithFuncE[ARG any](
+	name, help string,
+	run func(ctx blocks.Context, arg *ARG) error,
 ) *Command {
 	cmd := &Command{
 		name:     name,
@@ -101,12 +132,11 @@ func NewCommandWithFunc[ARG any](
 
 	// 生成闭包函数
 	// 注意：这里不解析参数，参数解析在 Exec 方法中统一处理
-	cmd.execFunc = func(ctx blocks.Context, arg any) {
+	cmd.execFunc = func(ctx blocks.Context, arg any) error {
 		if argPtr, ok := arg.(*ARG); ok {
-			run(ctx, argPtr)
-		} else {
-			panic(fmt.Sprintf("except type:%#T, got type:%#T", argType, arg))
+			return run(ctx, argPtr)
 		}
+		panic(fmt.Sprintf("except type:%#T, got type:%#T", argType, arg))
 	}
 
 	return cmd
@@ -134,8 +164,9 @@ func NewCommandWithFuncLegacy(
 
 	// 生成闭包函数
 	// 注意：这里不解析参数，参数解析在 Exec 方法中统一处理
-	cmd.execFunc = func(ctx blocks.Context, arg any) {
+	cmd.execFunc = func(ctx blocks.Context, arg any) error {
 		run(ctx)
+		return nil
 	}
 
 	return cmd
@@ -144,9 +175,39 @@ func NewCommandWithFuncLegacy(
 // NewCommand 创建一个自定义命令
 // 如果 custom 是结构体类型，会解析其字段作为参数定义
 func NewCommand(custom Commander) *Command {
+	cmd := newCommanderCommand(custom.Name(), custom.Help(), custom)
+	originalType := cmd.argType
+	// 生成执行函数
+	// 有参数，需要传递解析后的参数
+	cmd.execFunc = func(ctx blocks.Context, arg any) error {
+		if argPtr, ok := arg.(Commander); ok {
+			argPtr.Exec(ctx)
+			return nil
+		}
+		panic(fmt.Sprintf("except type:%#T, got type:%#T", originalType, arg))
+	}
+	return cmd
+}
+
+// NewCommandE 创建一个返回错误的自定义命令
+// 如果 custom 是结构体类型，会解析其字段作为参数定义
+func NewCommandE(custom CommanderE) *Command {
+	cmd := newCommanderCommand(custom.Name(), custom.Help(), custom)
+	originalType := cmd.argType
+	cmd.execFunc = func(ctx blocks.Context, arg any) error {
+		if argPtr, ok := arg.(CommanderE); ok {
+			return argPtr.Exec(ctx)
+		}
+		panic(fmt.Sprintf("except type:%#T, got type:%#T", originalType, arg))
+	}
+	return cmd
+}
+
+// newCommanderCommand 创建自定义命令, 解析参数定义. 执行函数由调用方设置
+func newCommanderCommand(name, help string, custom any) *Command {
 	cmd := &Command{
-		name:        custom.Name(),
-		help:        custom.Help(),
+		name:        name,
+		help:        help,
 		children:    make(map[string]*Command),
 		isCommander: true, // 标记为 Commander 类型
 	}
@@ -174,16 +235,6 @@ func NewCommand(custom Commander) *Command {
 		panic(fmt.Sprintf("except struct type, got type:%#T", structType))
 	}
 
-	// 生成执行函数
-	// 有参数，需要传递解析后的参数
-	cmd.execFunc = func(ctx blocks.Context, arg any) {
-		if argPtr, ok := arg.(Commander); ok {
-			argPtr.Exec(ctx)
-		} else {
-			panic(fmt.Sprintf("except type:%#T, got type:%#T", originalType, arg))
-		}
-	}
-
 	return cmd
 }
 
@@ -268,8 +319,9 @@ func (c *Command) hasSubcommand() bool {
 
 // Exec 执行命令
 // arg: 已解析的参数值（由 ExecCommand 传入）
-func (c *Command) Exec(ctx blocks.Context, arg any) {
+func (c *Command) Exec(ctx blocks.Context, arg any) error {
 	if c.execFunc != nil {
-		c.execFunc(ctx, arg)
+		return c.execFunc(ctx, arg)
 	}
+	return nil
 }
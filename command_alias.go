@@ -0,0 +1,339 @@
+package promptx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/completion"
+)
+
+// AliasManager 运行时别名管理接口
+type AliasManager interface {
+	// SetAlias 设置别名, value 为展开后的命令行
+	SetAlias(name, value string) error
+	// RemoveAlias 删除别名
+	RemoveAlias(name string) error
+	// Aliases 返回所有别名
+	Aliases() map[string]string
+}
+
+// SetAlias 设置别名
+// 通过接口判定，如果 ctx 实现了 AliasManager 接口则调用，否则返回错误
+func SetAlias(ctx blocks.Context, name, value string) error {
+	mgr, ok := ctx.(AliasManager)
+	if !ok {
+		return fmt.Errorf("context does not implement AliasManager interface")
+	}
+	return mgr.SetAlias(name, value)
+}
+
+// RemoveAlias 删除别名
+// 通过接口判定，如果 ctx 实现了 AliasManager 接口则调用，否则返回错误
+func RemoveAlias(ctx blocks.Context, name string) error {
+	mgr, ok := ctx.(AliasManager)
+	if !ok {
+		return fmt.Errorf("context does not implement AliasManager interface")
+	}
+	return mgr.RemoveAlias(name)
+}
+
+// aliasesOf 返回 ctx 中的别名, ctx 未实现 AliasManager 接口时返回 nil
+func aliasesOf(ctx blocks.Context) map[string]string {
+	if mgr, ok := ctx.(AliasManager); ok {
+		return mgr.Aliases()
+	}
+	return nil
+}
+
+// checkAliasName 检查别名名称, 名称不能包含空白、引号、操作符以及 =
+func checkAliasName(name string) error {
+	if name == "" || strings.ContainsFunc(name, needQuote) || strings.ContainsAny(name, "=$") {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+	return nil
+}
+
+// expandAliases 展开命令位置（行首以及 | ; && || 之后）的别名
+// 别名中的 $1..$9 替换为对应的参数, $@ 替换为所有参数. 使用了参数时, 参数不再追加到展开的命令之后.
+// 别名展开的结果中不会再次展开同名的别名, 避免递归.
+func expandAliases(line string, aliases map[string]string) string {
+	if len(aliases) == 0 {
+		return line
+	}
+	return expandAliasLine(line, aliases, make(map[string]bool))
+}
+
+// expandAliasLine 展开别名（内部实现）
+// expanding: 正在展开的别名
+func expandAliasLine(line string, aliases map[string]string, expanding map[string]bool) string {
+	result := lexLine(line)
+	if result.err() != nil {
+		// 由命令解析报告错误
+		return line
+	}
+	var (
+		sb     strings.Builder
+		runes  = []rune(line)
+		toks   = result.tokens
+		pos    int
+		cmdPos = true
+	)
+	for k := 0; k < len(toks); k++ {
+		tok := toks[k]
+		if tok.Op != "" {
+			// 重定向之后是文件名
+			cmdPos = tok.Op != opRedirect && tok.Op != opAppend
+			continue
+		}
+		if !cmdPos {
+			continue
+		}
+		cmdPos = false
+		// 使用引号或者转义的名称不展开
+		value, ok := aliases[tok.Value]
+		if !ok || tok.Raw != tok.Value || expanding[tok.Value] {
+			continue
+		}
+		// 命令的参数到下一个操作符为止
+		end := k + 1
+		for end < len(toks) && toks[end].Op == "" {
+			end++
+		}
+		args := make([]string, 0, end-k-1)
+		for _, arg := range toks[k+1 : end] {
+			args = append(args, arg.Value)
+		}
+		text, used := substituteAliasArgs(value, args)
+		expanding[tok.Value] = true
+		text = expandAliasLine(text, aliases, expanding)
+		delete(expanding, tok.Value)
+
+		sb.WriteString(string(runes[pos:tok.Start]))
+		sb.WriteString(text)
+		pos = tok.End
+		if used && end > k+1 {
+			pos = toks[end-1].End
+			k = end - 1
+		}
+	}
+	sb.WriteString(string(runes[pos:]))
+	return sb.String()
+}
+
+// substituteAliasArgs 替换别名中的 $1..$9 以及 $@, 返回是否使用了参数
+func substituteAliasArgs(value string, args []string) (string, bool) {
+	var (
+		sb    strings.Builder
+		runes = []rune(value)
+		used  bool
+	)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '$' || i+1 >= len(runes) {
+			sb.WriteRune(runes[i])
+			continue
+		}
+		switch next := runes[i+1]; {
+		case next == '@':
+			quoted := make([]string, 0, len(args))
+			for _, arg := range args {
+				quoted = append(quoted, quoteArg(arg))
+			}
+			sb.WriteString(strings.Join(quoted, " "))
+		case next >= '1' && next <= '9':
+			if n := int(next - '1'); n < len(args) {
+				sb.WriteString(quoteArg(args[n]))
+			}
+		default:
+			sb.WriteRune(runes[i])
+			continue
+		}
+		used = true
+		i++
+	}
+	return sb.String(), used
+}
+
+// aliasFileOf 别名文件路径, 保存在历史记录文件旁边
+func aliasFileOf(history string) string {
+	if history == "" {
+		return ""
+	}
+	return history + ".alias"
+}
+
+// loadAliases 从文件加载别名, 格式与 alias 命令的参数相同: name='value'
+func loadAliases(file string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return aliases, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields, err := splitCommandLine(line)
+		if err != nil || len(fields) != 1 {
+			continue
+		}
+		name, value, ok := strings.Cut(fields[0], "=")
+		if ok && checkAliasName(name) == nil {
+			aliases[name] = value
+		}
+	}
+	return aliases, scanner.Err()
+}
+
+// saveAliases 保存别名到文件
+func saveAliases(file string, aliases map[string]string) error {
+	var sb strings.Builder
+	for _, name := range sortedAliasNames(aliases) {
+		sb.WriteString(formatAlias(name, aliases[name]))
+		sb.WriteByte('\n')
+	}
+	return os.WriteFile(file, []byte(sb.String()), 0o644)
+}
+
+// formatAlias 格式化别名, 可以作为 alias 命令的参数
+func formatAlias(name, value string) string {
+	return name + "=" + quoteArg(value)
+}
+
+// sortedAliasNames 排序后的别名名称
+func sortedAliasNames(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// aliasSuggest 补全别名名称
+func aliasSuggest(ctx blocks.Context, partial *token, input []rune) []*completion.Suggest {
+	aliases := aliasesOf(ctx)
+	var suggests []*completion.Suggest
+	for _, name := range sortedAliasNames(aliases) {
+		if !completion.FuzzyMatchRunes([]rune(name), input) {
+			continue
+		}
+		suggests = append(suggests, &completion.Suggest{
+			Text:        completeText(partial, name),
+			Description: "alias: " + aliases[name],
+		})
+	}
+	return suggests
+}
+
+// aliasArgs alias 命令参数
+type aliasArgs struct {
+	Definitions []string `arg:"name=command,optional"`
+}
+
+// unaliasArgs unalias 命令参数
+type unaliasArgs struct {
+	Names []string `arg:"alias name"`
+}
+
+// newAliasCommand 创建 alias 命令
+// alias: 列出所有别名; alias name: 显示别名; alias name='command $1': 设置别名
+func newAliasCommand() *Command {
+	return NewCommandWithFuncE("alias", "define or display aliases", func(ctx blocks.Context, arg *aliasArgs) error {
+		aliases := aliasesOf(ctx)
+		if len(arg.Definitions) == 0 {
+			for _, name := range sortedAliasNames(aliases) {
+				ctx.Println("alias " + formatAlias(name, aliases[name]))
+			}
+			return nil
+		}
+		// 先检查所有参数, 任一参数错误时不修改别名
+		defined := make(map[string]bool)
+		for _, def := range arg.Definitions {
+			name, _, ok := strings.Cut(def, "=")
+			if ok {
+				if err := checkAliasName(name); err != nil {
+					return err
+				}
+				defined[name] = true
+				continue
+			}
+			if _, found := aliases[name]; !found && !defined[name] {
+				return fmt.Errorf("alias %s not found", name)
+			}
+		}
+		for _, def := range arg.Definitions {
+			name, value, ok := strings.Cut(def, "=")
+			if !ok {
+				ctx.Println("alias " + formatAlias(name, aliasesOf(ctx)[name]))
+				continue
+			}
+			if err := SetAlias(ctx, name, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// newUnaliasCommand 创建 unalias 命令
+func newUnaliasCommand() *Command {
+	return NewCommandWithFuncE("unalias", "remove aliases", func(ctx blocks.Context, arg *unaliasArgs) error {
+		for _, name := range arg.Names {
+			if err := RemoveAlias(ctx, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).ArgComplete("Names", func(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+		aliases := aliasesOf(ctx)
+		var suggests []*completion.Suggest
+		for _, name := range sortedAliasNames(aliases) {
+			if strings.HasPrefix(name, input) {
+				suggests = append(suggests, &completion.Suggest{Text: name, Description: aliases[name]})
+			}
+		}
+		return suggests
+	})
+}
+
+// SetAlias 设置别名（实现 AliasManager 接口）, 设置了历史记录文件时保存到文件
+func (p *promptx) SetAlias(name, value string) error {
+	if err := checkAliasName(name); err != nil {
+		return err
+	}
+	p.aliases[name] = value
+	return p.saveAliases()
+}
+
+// RemoveAlias 删除别名（实现 AliasManager 接口）
+func (p *promptx) RemoveAlias(name string) error {
+	if _, ok := p.aliases[name]; !ok {
+		return fmt.Errorf("alias %s not found", name)
+	}
+	delete(p.aliases, name)
+	return p.saveAliases()
+}
+
+// Aliases 返回所有别名（实现 AliasManager 接口）
+func (p *promptx) Aliases() map[string]string {
+	return p.aliases
+}
+
+// saveAliases 保存别名到文件
+func (p *promptx) saveAliases() error {
+	if p.aliasFile == "" {
+		return nil
+	}
+	return saveAliases(p.aliasFile, p.aliases)
+}
@@ -0,0 +1,128 @@
+package promptx
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string]string{
+		"ll":    "list -l",
+		"dev":   "login dev $1",
+		"both":  "echo $2 $1",
+		"all":   "echo start $@ end",
+		"loop":  "loop -x",
+		"chain": "ll; dev bob",
+		"quote": "echo '$1'",
+	}
+	var scenarioTable = []struct {
+		line     string
+		expected string
+	}{
+		{line: "ll", expected: "list -l"},
+		{line: "ll /tmp", expected: "list -l /tmp"},
+		{line: "dev alice", expected: "login dev alice"},
+		{line: "dev 'my account' extra", expected: "login dev 'my account'"},
+		{line: "dev", expected: "login dev "},
+		{line: "both a b", expected: "echo b a"},
+		{line: "all 'a b' c", expected: "echo start 'a b' c end"},
+		{line: "loop", expected: "loop -x"},
+		{line: "chain", expected: "list -l; login dev bob"},
+		{line: "echo ll | ll && x ll", expected: "echo ll | list -l && x ll"},
+		{line: "echo a > ll", expected: "echo a > ll"},
+		{line: "'ll'", expected: "'ll'"},
+		{line: "dev a; ll b", expected: "login dev a; list -l b"},
+		{line: "ll 'unterminated", expected: "ll 'unterminated"},
+	}
+	for _, s := range scenarioTable {
+		if actual := expandAliases(s.line, aliases); actual != s.expected {
+			t.Errorf("%q: should expand to %q, but got %q", s.line, s.expected, actual)
+		}
+	}
+}
+
+func TestAliasCommand(t *testing.T) {
+	var out bytes.Buffer
+	history := filepath.Join(t.TempDir(), "history")
+	newAliasPromptx := func() Promptx {
+		cfg := NewConfig()
+		cfg.Hardware().
+			InputParser(testutil.NoTerminalParser{}).
+			OutputWriter(output.NewConsoleWriter(&out))
+		cfg.Common().History(history)
+		cfg.DefaultCommandGroup().AliasCommand().AddCommand(
+			NewCommandWithFunc("hello", "say hello", func(ctx Context, arg *scriptTestArgs) {
+				ctx.Println("hello", arg.Name)
+			}),
+		)
+		return cfg.Build()
+	}
+
+	p := newAliasPromptx()
+	err := p.RunArgs([]string{
+		"alias hi='hello $1' hb='hello bob'",
+		"hi alice",
+		"hb",
+		"alias hi",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "hello alice\nhello bob\nalias hi='hello $1'\n"
+	if out.String() != expected {
+		t.Errorf("output should be %q, but got %q", expected, out.String())
+	}
+	if err = p.RunArgs([]string{"alias 'a b'=x"}); err == nil {
+		t.Errorf("invalid alias name should return error")
+	}
+	// 定义中的逗号属于命令
+	out.Reset()
+	if err = p.RunArgs([]string{"alias hab='hello a,b'", "hab"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out.String() != "hello a,b\n" {
+		t.Errorf("output should be %q, but got %q", "hello a,b\n", out.String())
+	}
+	// 任一参数错误时不修改别名, 也不保存文件
+	if err = p.RunArgs([]string{"alias hx='hello x' nope"}); err == nil {
+		t.Errorf("unknown alias should return error")
+	}
+	if _, ok := p.(*promptx).Aliases()["hx"]; ok {
+		t.Errorf("alias should not be set when a definition is invalid")
+	}
+	if err = p.RunArgs([]string{"unalias hab"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// 别名保存在历史记录文件旁边
+	out.Reset()
+	p = newAliasPromptx()
+	if err = p.RunArgs([]string{"unalias hb", "hi carol", "alias"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = "hello carol\nalias hi='hello $1'\n"
+	if out.String() != expected {
+		t.Errorf("output should be %q, but got %q", expected, out.String())
+	}
+	if err = p.RunArgs([]string{"unalias hb"}); err == nil {
+		t.Errorf("remove unknown alias should return error")
+	}
+	aliases, err := loadAliases(history + ".alias")
+	if err != nil || len(aliases) != 1 || aliases["hi"] != "hello $1" {
+		t.Errorf("aliases file should contain hi, but got %v %v", aliases, err)
+	}
+
+	// 补全别名
+	var texts []string
+	for _, v := range p.(*promptx).root.FindSuggestWithContext(p, *buffer.NewDocumentWithCursor("h", 1)) {
+		texts = append(texts, v.Text)
+	}
+	if len(texts) != 2 || texts[0] != "hello" || texts[1] != "hi" {
+		t.Errorf("suggests should be [hello hi], but got %v", texts)
+	}
+}
@@ -0,0 +1,112 @@
+package promptx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// commandList 使用 ; && || 连接的命令序列, 例如: build && deploy || rollback
+type commandList []*commandListItem
+
+// commandListItem 命令序列中的一个管道命令
+type commandListItem struct {
+	// 与前一个命令的连接符, 第一个命令为空
+	op string
+	// 原始输入
+	line string
+	// 管道命令
+	pipeline *pipeline
+}
+
+// parseCommandList 解析命令序列, 按照引号外的 ; && || 拆分为多个管道命令
+// 结尾的 ; 可以省略后面的命令, && || 之后必须有命令
+// lookup 用于展开命令行中的变量, 为 nil 时不展开
+func parseCommandList(root *Command, line string, lookup func(name string) (string, bool)) (commandList, error) {
+	result := lexLineVars(line, lookup)
+	if err := result.err(); err != nil {
+		return nil, err
+	}
+	var (
+		list  commandList
+		runes = []rune(line)
+		toks  []*token
+		op    string
+	)
+	add := func(next string) error {
+		if len(toks) == 0 {
+			return fmt.Errorf("syntax error near %s", next)
+		}
+		pl, err := parsePipelineTokens(root, runes, toks)
+		if err != nil {
+			return err
+		}
+		list = append(list, &commandListItem{
+			op:       op,
+			line:     string(runes[toks[0].Start:toks[len(toks)-1].End]),
+			pipeline: pl,
+		})
+		toks = nil
+		return nil
+	}
+	for _, tok := range result.tokens {
+		switch tok.Op {
+		case opSeq, opAnd, opOr:
+			if err := add(tok.Op); err != nil {
+				return nil, err
+			}
+			op = tok.Op
+		default:
+			toks = append(toks, tok)
+		}
+	}
+	if len(toks) > 0 || (op != "" && op != opSeq) {
+		if err := add(op); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// found 是否找到了第一个命令
+func (l commandList) found() bool {
+	return len(l) > 0 && l[0].pipeline.found()
+}
+
+// check 检查命令序列中的命令都存在
+func (l commandList) check() error {
+	for _, item := range l {
+		if err := item.pipeline.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run 依次执行命令序列, 返回最后一个执行的命令的错误
+// && || 根据前一个执行的命令的结果决定是否执行, 命令被取消时不再执行后面的命令
+func (l commandList) run(exec func(item *commandListItem) error) (err error) {
+	for k, item := range l {
+		if k > 0 && !item.shouldRun(err) {
+			continue
+		}
+		err = exec(item)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	return err
+}
+
+// shouldRun 根据前一个命令的执行结果判断是否执行命令
+// && 在前一个命令成功时执行, || 在前一个命令失败时执行, ; 总是执行
+func (item *commandListItem) shouldRun(lastErr error) bool {
+	switch item.op {
+	case opAnd:
+		return lastErr == nil
+	case opOr:
+		return lastErr != nil
+	default:
+		return true
+	}
+}
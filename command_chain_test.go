@@ -0,0 +1,118 @@
+package promptx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+func TestParseCommandList(t *testing.T) {
+	root := newPipeTestRoot()
+	var scenarioTable = []struct {
+		line     string
+		expected []string
+		err      string
+	}{
+		{line: "list", expected: []string{"list"}},
+		{line: "list; fail 1", expected: []string{"list", "; fail 1"}},
+		{line: "list && fail 1 || list | grep dev", expected: []string{"list", "&& fail 1", "|| list | grep dev"}},
+		{line: "fail '1;2'&&list;", expected: []string{"fail '1;2'", "&& list"}},
+		{line: "; list", err: "syntax error near ;"},
+		{line: "list && ", err: "syntax error near &&"},
+		{line: "list ;; list", err: "syntax error near ;"},
+		{line: "list || && list", err: "syntax error near &&"},
+	}
+	for _, s := range scenarioTable {
+		list, err := parseCommandList(root, s.line, nil)
+		if s.err != "" {
+			if err == nil || err.Error() != s.err {
+				t.Errorf("%q: error should be %q, but got %v", s.line, s.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", s.line, err)
+			continue
+		}
+		var items []string
+		for _, item := range list {
+			items = append(items, strings.TrimSpace(item.op+" "+item.line))
+		}
+		if strings.Join(items, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("%q: items should be %q, but got %q", s.line, s.expected, items)
+		}
+	}
+}
+
+func TestRunCommandList(t *testing.T) {
+	errFailed := errors.New("failed")
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(
+		NewCommandWithFuncE("echo", "echo args", func(ctx Context, arg *struct {
+			Words []string `arg:"words"`
+		}) error {
+			ctx.Println(strings.Join(arg.Words, " "))
+			return nil
+		}),
+		NewCommandWithFuncLegacy("fail", "always fail", func(ctx Context) {
+			panic(errFailed)
+		}),
+		NewCommandWithFuncE("cancel", "canceled", func(ctx Context, arg *struct {
+			Words []string `arg:"words,optional"`
+		}) error {
+			return context.Canceled
+		}),
+	)
+
+	var scenarioTable = []struct {
+		line     string
+		expected string
+		failed   bool
+	}{
+		{line: "echo a; echo b", expected: "a\nb\n"},
+		{line: "fail; echo b", expected: "b\n"},
+		{line: "echo a && echo b", expected: "a\nb\n"},
+		{line: "fail && echo b", expected: "", failed: true},
+		{line: "echo a || echo b", expected: "a\n"},
+		{line: "fail || echo b", expected: "b\n"},
+		{line: "fail && echo a || echo b", expected: "b\n"},
+		{line: "echo a || echo b && echo c", expected: "a\nc\n"},
+		{line: "echo a && fail", expected: "a\n", failed: true},
+		{line: "cancel; echo b", expected: "", failed: true},
+		{line: "echo 'a;b' && echo a | grep a", expected: "a;b\na\n"},
+		// grep 没有匹配的行时执行失败, 与 shell 一致
+		{line: "echo a | grep x && echo found", expected: "", failed: true},
+		{line: "echo a | grep x || echo missing", expected: "missing\n"},
+		{line: "echo a | grep a && echo found", expected: "a\nfound\n"},
+		{line: "echo a | grep -c x || echo missing", expected: "0\nmissing\n"},
+		{line: "echo a | grep x | wc -l", expected: "0\n"},
+	}
+	for _, s := range scenarioTable {
+		list, err := parseCommandList(root, s.line, nil)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", s.line, err)
+			continue
+		}
+		var out bytes.Buffer
+		err = list.run(func(item *commandListItem) error {
+			return item.pipeline.run(context.Background(), func(in io.Reader, w io.Writer) blocks.Context {
+				if w == nil {
+					w = &out
+				}
+				return &pipeTestContext{in: in, out: w}
+			})
+		})
+		if out.String() != s.expected {
+			t.Errorf("%q: output should be %q, but got %q", s.line, s.expected, out.String())
+		}
+		if (err != nil) != s.failed {
+			t.Errorf("%q: failed should be %v, but got %v", s.line, s.failed, fmt.Sprint(err))
+		}
+	}
+}
@@ -2,195 +2,293 @@ package promptx
 
 import (
 	"strings"
-	"unicode"
 
+	"github.com/aggronmagi/promptx/v2/blocks"
 	"github.com/aggronmagi/promptx/v2/buffer"
 	"github.com/aggronmagi/promptx/v2/completion"
 )
 
 // FindSuggest 查找建议（用于自动补全）
 func (c *Command) FindSuggest(doc buffer.Document) []*completion.Suggest {
-	c.fixChildren()
-	line := []rune(doc.TextBeforeCursor())
-	return c.findSuggest(line, doc.Text, nil)
+	return c.FindSuggestWithContext(nil, doc)
 }
 
-// findSuggest 查找建议（内部实现）
-func (c *Command) findSuggest(line []rune, origLine string, cmds []*Command) []*completion.Suggest {
-	// 确保子命令映射已更新
-	c.fixChildren()
+// FindSuggestWithContext 查找建议, ctx 传递给参数的动态选项函数
+func (c *Command) FindSuggestWithContext(ctx blocks.Context, doc buffer.Document) []*completion.Suggest {
+	text := doc.TextBeforeCursor()
+	result := lexLine(text)
+	suggests := c.suggestTokens(ctx, result)
+	// 补全器会删除光标前最后一个空格之后的内容, 操作符前后没有空格时（例如 a|hel）,
+	// 需要将正在输入的内容之前被删除的部分补回到建议中
+	prefix := operatorPrefix([]rune(text), result)
+	if prefix == "" {
+		return suggests
+	}
+	fixed := make([]*completion.Suggest, 0, len(suggests))
+	for _, v := range suggests {
+		fixed = append(fixed, &completion.Suggest{Text: prefix + v.Text, Description: v.Description})
+	}
+	return fixed
+}
 
-	// 去除左侧空格
-	line = trimSpaceLeft(line)
-	if len(line) == 0 && c.config != nil {
-		// 如果输入为空，返回所有子命令
-		var suggests []*completion.Suggest
-		for _, child := range c.subCommands {
-			suggests = append(suggests, child.suggest())
-			// 也添加别名
-			for _, alias := range child.aliases {
-				suggests = append(suggests, &completion.Suggest{
-					Text:        alias,
-					Description: child.help,
-				})
-			}
+// operatorPrefix 光标前最后一个空格与正在输入的内容之间的文本
+func operatorPrefix(text []rune, result *lexResult) string {
+	start := len(text)
+	if n := len(result.tokens); n > 0 && !result.trailingSpace && result.tokens[n-1].Op == "" {
+		start = result.tokens[n-1].Start
+	}
+	begin := 0
+	for i := len(text) - 1; i >= 0; i-- {
+		if text[i] == ' ' {
+			begin = i + 1
+			break
 		}
+	}
+	if begin >= start {
+		return ""
+	}
+	return string(text[begin:start])
+}
+
+// suggestTokens 根据光标前的 token 查找建议
+func (c *Command) suggestTokens(ctx blocks.Context, result *lexResult) []*completion.Suggest {
+	c.fixChildren()
+	words := result.tokens
+	// 光标前不是空白, 最后一个 token 是正在输入的内容. 光标紧跟操作符时没有正在输入的内容
+	var partial *token
+	if len(words) > 0 && !result.trailingSpace && words[len(words)-1].Op == "" {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	// 补全变量名称
+	if suggests, ok := variableSuggest(ctx, partial); ok {
 		return suggests
 	}
+	// 命令序列、管道以及重定向只补全最后一个操作符之后的内容
+	for k := len(words) - 1; k >= 0; k-- {
+		switch words[k].Op {
+		case "":
+			continue
+		case opRedirect, opAppend:
+			return redirectSuggest(words[k+1:], partial)
+		case opPipe:
+			return c.pipeSuggest(ctx, words[k+1:], partial)
+		}
+		return c.findSuggest(ctx, words[k+1:], partial, nil)
+	}
+	return c.findSuggest(ctx, words, partial, nil)
+}
 
-	var offset int
-	goNext := false
-	var nextCmd *Command
-	var suggest []*completion.Suggest
+// redirectSuggest 补全重定向的文件名
+func redirectSuggest(words []*token, partial *token) []*completion.Suggest {
+	if len(words) > 0 {
+		return nil
+	}
+	input := ""
+	if partial != nil {
+		input = partial.Value
+	}
+	var suggests []*completion.Suggest
+	for _, opt := range completePath(input, false) {
+		suggests = append(suggests, &completion.Suggest{
+			Text:        completeText(partial, opt.Text),
+			Description: "output file",
+		})
+	}
+	return suggests
+}
 
-	// 匹配命令
-	matchCmd := func(name []rune, cmd *Command, s *completion.Suggest) {
-		// 如果输入长度小于命令名长度，进行模糊匹配
-		if len(line) < len(name) {
-			if !completion.FuzzyMatchRunes(name, line) {
-				return
-			}
-			if s == nil {
-				s = cmd.suggest()
-			}
-			suggest = append(suggest, s)
-			offset = len(line)
-			nextCmd = cmd
-			return
+// pipeSuggest 补全管道之后的命令, 包含内置的过滤命令
+func (c *Command) pipeSuggest(ctx blocks.Context, words []*token, partial *token) []*completion.Suggest {
+	if len(words) > 0 && c.findChildCmd(words[0].Value) != nil {
+		return c.findSuggest(ctx, words, partial, nil)
+	}
+	filters := filterCommands()
+	if len(words) > 0 {
+		return filters.findSuggest(ctx, words, partial, nil)
+	}
+	suggests := c.findSuggest(ctx, words, partial, nil)
+	for _, suggest := range filters.findSuggest(ctx, words, partial, nil) {
+		if c.findChildCmd(suggest.Text) == nil {
+			suggests = append(suggests, suggest)
 		}
+	}
+	return suggests
+}
 
-		// 需要查找子命令或匹配当前命令
-		if !hasPrefix(line, name) {
-			return
-		}
+// findSuggest 查找建议（内部实现）
+// words: 已经输入完成的 token
+// partial: 正在输入的 token, 为 nil 表示光标前是空白
+func (c *Command) findSuggest(ctx blocks.Context, words []*token, partial *token, cmds []*Command) []*completion.Suggest {
+	// 确保子命令映射已更新
+	c.fixChildren()
+	cmds = append(cmds, c)
 
-		cname := trimFirstSpace(line)
-		if !equal(name, cname) {
-			return
+	// 匹配子命令
+	if len(words) > 0 {
+		if child := c.findChildCmd(words[0].Value); child != nil {
+			return child.findSuggest(ctx, words[1:], partial, cmds)
 		}
+		// 已经进入参数部分
+		return c.findArgSuggest(ctx, words, partial)
+	}
+	// 没有子命令时补全参数
+	if !c.hasSubcommand() || (partial != nil && strings.HasPrefix(partial.Value, "-")) {
+		return c.findArgSuggest(ctx, nil, partial)
+	}
 
-		if s == nil {
-			s = cmd.suggest()
-		}
+	var input []rune
+	if partial != nil {
+		input = []rune(partial.Value)
+	}
 
-		if len(line) > len(name) {
-			nextCmd = cmd
-			goNext = true
+	var suggests []*completion.Suggest
+	// 匹配命令
+	matchCmd := func(name string, cmd *Command) {
+		if !completion.FuzzyMatchRunes([]rune(name), input) {
+			return
 		}
-		suggest = append(suggest, s)
-		offset = len(name)
+		suggests = append(suggests, &completion.Suggest{
+			Text:        completeText(partial, name),
+			Description: cmd.help,
+		})
 	}
 
 	// 遍历子命令
 	for _, child := range c.subCommands {
 		// 命令名
 		if child.name != "" {
-			matchCmd([]rune(child.name), child, nil)
+			matchCmd(child.name, child)
 		}
 		// 命令别名
 		for _, alias := range child.aliases {
-			matchCmd([]rune(alias), child, &completion.Suggest{
-				Text:        alias,
-				Description: child.help,
-			})
+			matchCmd(alias, child)
 		}
 	}
-
-	cmds = append(cmds, c)
-
-	// 如果没有找到建议，返回
-	if len(suggest) == 0 {
-		return nil
+	// 命令组中补全运行时定义的别名
+	if len(cmds) == 1 && c.config != nil {
+		suggests = append(suggests, aliasSuggest(ctx, partial, input)...)
 	}
+	return suggests
+}
 
-	// 如果有多个建议，返回所有建议
-	if len(suggest) != 1 {
-		return suggest
+// findArgSuggest 查找参数建议
+// words: 命令名之后已经输入完成的参数
+// partial: 正在输入的参数
+func (c *Command) findArgSuggest(ctx blocks.Context, words []*token, partial *token) []*completion.Suggest {
+	values := make([]string, 0, len(words))
+	terminated := false
+	for _, word := range words {
+		values = append(values, word.Value)
+		terminated = terminated || word.Value == flagTerminator
+	}
+	input := ""
+	if partial != nil {
+		input = partial.Value
 	}
 
-	// 只有一个建议，继续查找子命令
-	// 跳过当前命令名，尝试查找子命令
-	for i := offset; i < len(line); i++ {
-		if line[i] == ' ' {
-			continue
+	var (
+		target *ArgDef
+		// 补全文本的前缀, 用于 --name=value 形式
+		prefix string
+	)
+	if !terminated {
+		// 正在输入命名参数的值
+		if n := len(values); n > 0 && isFlagArg(values[n-1]) && !strings.Contains(values[n-1], "=") {
+			def := findFlag(c.argDefs, strings.TrimLeft(values[n-1], "-"), !strings.HasPrefix(values[n-1], "--"))
+			if def != nil && !def.IsBoolFlag() {
+				target = def
+				values = values[:n-1]
+			}
 		}
-		if nextCmd != nil {
-			return nextCmd.findSuggest(line[i:], origLine, cmds)
+		// 正在输入命名参数, 负数作为位置参数处理
+		if target == nil && strings.HasPrefix(input, "-") && (len(input) < 2 || input == flagTerminator || isFlagArg(input)) {
+			name, value, hasValue := strings.Cut(input, "=")
+			if !hasValue {
+				return c.findFlagSuggest(values, partial)
+			}
+			target = findFlag(c.argDefs, strings.TrimLeft(name, "-"), !strings.HasPrefix(name, "--"))
+			prefix, input = name+"=", value
 		}
-		return nil
 	}
 
-	// 匹配当前命令，查找子命令
-	if goNext && nextCmd != nil {
-		return nextCmd.findSuggest(nil, origLine, cmds)
+	flags, positional, err := splitFlagArgs(c.argDefs, values)
+	if err != nil {
+		return nil
 	}
-
-	return suggest
-}
-
-// suggest 创建建议
-func (c *Command) suggest() *completion.Suggest {
-	return &completion.Suggest{
-		Text:        c.name,
-		Description: c.help,
+	bound := bindArgs(c.argDefs, flags, positional)
+	if target == nil {
+		target = positionalArgDef(c.argDefs, len(positional))
+	}
+	if target == nil {
+		return nil
+	}
+	// 当前参数之前已经确定的参数, 以及多值参数已经输入的值
+	var before int
+	for before = 0; before < len(c.argDefs) && c.argDefs[before] != target; before++ {
 	}
+	args := argValuesMap(c.argDefs[:before+1], bound[:before+1])
+	return argValueSuggest(ctx, target, args, partial, prefix, input)
 }
 
-// 辅助函数
-
-// trimSpaceLeft 去除左侧空格
-func trimSpaceLeft(in []rune) []rune {
-	for i, r := range in {
-		if !unicode.IsSpace(r) {
-			return in[i:]
+// positionalArgDef 返回第 index 个位置参数的定义
+// 最后一个位置参数为 slice/map 类型时接收剩余的所有位置参数
+func positionalArgDef(defs []*ArgDef, index int) *ArgDef {
+	last := lastPositional(defs)
+	for i, def := range defs {
+		if def.IsFlag() {
+			continue
+		}
+		if index == 0 || (i == last && isMultiValueType(def.Type)) {
+			return def
 		}
+		index--
 	}
 	return nil
 }
 
-// hasPrefix 检查前缀
-func hasPrefix(r, prefix []rune) bool {
-	if len(r) < len(prefix) {
-		return false
-	}
-	for i := 0; i < len(prefix); i++ {
-		if r[i] != prefix[i] {
-			return false
+// findFlagSuggest 查找命名参数名称的建议
+// values: 命令名之后已经输入完成的参数
+// partial: 正在输入的命名参数
+func (c *Command) findFlagSuggest(values []string, partial *token) []*completion.Suggest {
+	// 已经使用过的命名参数不再提示
+	used := make(map[*ArgDef]bool)
+	for _, value := range values {
+		if !isFlagArg(value) {
+			continue
 		}
-	}
-	return true
-}
-
-// equal 比较两个 rune 切片
-func equal(a, b []rune) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := 0; i < len(a); i++ {
-		if a[i] != b[i] {
-			return false
+		name, _, _ := strings.Cut(strings.TrimLeft(value, "-"), "=")
+		if def := findFlag(c.argDefs, name, !strings.HasPrefix(value, "--")); def != nil {
+			used[def] = true
 		}
 	}
-	return true
-}
 
-// trimFirstSpace 去除第一个空格及其之前的内容
-func trimFirstSpace(in []rune) []rune {
-	for i, r := range in {
-		if unicode.IsSpace(r) {
-			if i+1 < len(in) {
-				return in[i+1:]
+	var suggests []*completion.Suggest
+	for _, def := range c.argDefs {
+		if !def.IsFlag() || used[def] {
+			continue
+		}
+		for _, name := range []string{"--" + def.Flag, "-" + def.Short} {
+			if len(name) < 2 || name == "--" {
+				continue
 			}
-			return nil
+			if !strings.HasPrefix(name, partial.Value) {
+				continue
+			}
+			suggests = append(suggests, &completion.Suggest{
+				Text:        completeText(partial, name),
+				Description: def.Prompt,
+			})
 		}
 	}
-	return in
+	return suggests
 }
 
 // createCompleter 创建自动补全器
+// ctx: 传递给参数动态选项函数的上下文
 // root: 根命令（可能是命令组的根命令）
 // commandPrefix: 命令前缀（如果有）
-func createCompleter(root *Command, commandPrefix string) func(buffer.Document) []*completion.Suggest {
+func createCompleter(ctx blocks.Context, root *Command, commandPrefix string) func(buffer.Document) []*completion.Suggest {
 	return func(doc buffer.Document) []*completion.Suggest {
 		text := doc.Text
 
@@ -216,6 +314,6 @@ func createCompleter(root *Command, commandPrefix string) func(buffer.Document)
 		}
 
 		// 使用命令的 FindSuggest
-		return root.FindSuggest(doc)
+		return root.FindSuggestWithContext(ctx, doc)
 	}
 }
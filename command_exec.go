@@ -1,8 +1,8 @@
 package promptx
 
 import (
+	"fmt"
 	"runtime/debug"
-	"strings"
 
 	"github.com/aggronmagi/promptx/v2/blocks"
 )
@@ -24,9 +24,18 @@ type commandContext struct {
 
 // parseCommand 解析命令
 func parseCommand(root *Command, line string) (*commandContext, error) {
-	fields := strings.Fields(line)
+	fields, err := splitCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommandFields(root, fields, line), nil
+}
+
+// parseCommandFields 从分词结果中解析命令链以及参数
+// line 为命令对应的原始输入
+func parseCommandFields(root *Command, fields []string, line string) *commandContext {
 	if len(fields) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	ctx := &commandContext{
@@ -64,61 +73,79 @@ func parseCommand(root *Command, line string) (*commandContext, error) {
 		ctx.cur = root
 	}
 
-	return ctx, nil
+	return ctx
 }
 
 // execCommand 执行命令
-// 返回 true 表示找到并执行了命令，false 表示未找到命令
-func execCommand(ctx blocks.Context, root *Command, line string) bool {
+// 返回 find 表示是否找到命令, err 为参数检查或者命令执行的错误
+func execCommand(ctx blocks.Context, root *Command, line string) (find bool, err error) {
 	cmdCtx, err := parseCommand(root, line)
 	if err != nil {
-		ctx.Printf("解析命令失败: %v\n", err)
-		return false
+		return false, err
 	}
 
 	if cmdCtx == nil {
-		return false
+		return false, nil
 	}
 
 	// 检查是否有命令
-	if cmdCtx.cur == nil || cmdCtx.cur == root {
+	if !cmdCtx.found() {
 		// 找不到命令，返回 false
-		return false
+		return false, nil
 	}
 
-	// 解析参数
-	var arg any
-	if len(cmdCtx.cur.argDefs) > 0 && cmdCtx.cur.argType != nil {
-		// 检查参数
-		checkedArgs, err := checkArgs(ctx, cmdCtx.cur.argDefs, cmdCtx.args)
-		if err != nil {
-			ctx.Printf("参数检查失败: %v\n", err)
-			return false
-		}
+	return true, runCommand(ctx, cmdCtx)
+}
 
-		// 根据命令类型创建参数值
-		if cmdCtx.cur.isCommander {
-			// Commander 类型：使用保存的原始类型
-			argValue := createArgValueForCommander(cmdCtx.cur.argDefs, cmdCtx.cur.argType, checkedArgs)
-			if argValue.IsValid() {
-				arg = argValue.Interface()
-			}
-		} else {
-			// 普通泛型命令：创建指针
-			argValue := createArgValueFromStrings(cmdCtx.cur.argDefs, cmdCtx.cur.argType, checkedArgs)
-			if argValue.IsValid() && argValue.CanAddr() {
-				arg = argValue.Addr().Interface()
-			}
-		}
-	}
+// found 是否找到了命令
+func (c *commandContext) found() bool {
+	return c != nil && c.cur != nil && c.cur != c.root
+}
+
+// startsWithCommand 命令行的第一个单词是否为命令
+func startsWithCommand(root *Command, line string) bool {
+	toks := lexLine(line).tokens
+	return len(toks) > 0 && toks[0].Op == "" && root.findChildCmd(toks[0].Value) != nil
+}
 
-	// 执行命令
+// runCommand 执行命令
+// 参数在中间件内层检查, 中间件可以在交互输入以及打印用法之前拒绝执行
+func runCommand(ctx blocks.Context, cmdCtx *commandContext) (err error) {
 	defer func() {
 		if p := recover(); p != nil {
-			ctx.Printf("执行命令失败: %v\n%s", p, string(debug.Stack()))
+			err = fmt.Errorf("panic: %v\n%s", p, string(debug.Stack()))
 		}
 	}()
 
-	cmdCtx.cur.Exec(ctx, arg)
-	return true
+	handler := chainMiddlewares(cmdCtx.root, cmdCtx.cmds, execHandler)
+	return handler(ctx, &Invocation{Commands: cmdCtx.cmds, Args: cmdCtx.args, Line: cmdCtx.line})
+}
+
+// parseCommandArg 检查参数并创建命令链中最后一个命令的参数值
+func parseCommandArg(ctx blocks.Context, cmds []*Command, args []string) (arg any, err error) {
+	cur := cmds[len(cmds)-1]
+	if len(cur.argDefs) == 0 || cur.argType == nil {
+		return nil, nil
+	}
+	// 检查参数
+	checkedArgs, err := checkArgs(ctx, cur.argDefs, args)
+	if err != nil {
+		return nil, &UsageError{Usage: commandUsage(cmds), Err: err}
+	}
+
+	// 根据命令类型创建参数值
+	if cur.isCommander {
+		// Commander 类型：使用保存的原始类型
+		argValue := createArgValueForCommander(cur.argDefs, cur.argType, checkedArgs)
+		if argValue.IsValid() {
+			arg = argValue.Interface()
+		}
+	} else {
+		// 普通泛型命令：创建指针
+		argValue := createArgValueFromStrings(cur.argDefs, cur.argType, checkedArgs)
+		if argValue.IsValid() && argValue.CanAddr() {
+			arg = argValue.Addr().Interface()
+		}
+	}
+	return arg, nil
 }
@@ -0,0 +1,133 @@
+package promptx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// filterCommands 管道中可以使用的内置过滤命令
+// 命令组中存在同名命令时优先使用命令组中的命令
+var filterCommands = sync.OnceValue(func() *Command {
+	return (&Command{}).SubCommands(
+		newGrepCommand(),
+		newHeadCommand(),
+		newWcCommand(),
+	)
+})
+
+// ErrNoMatch grep 没有匹配的行. 与 grep 的退出状态一致, 执行状态为 StatusError, 默认不输出错误信息
+var ErrNoMatch = errors.New("no lines matched")
+
+// scanLines 逐行读取命令输入
+func scanLines(ctx blocks.Context, fn func(line string)) error {
+	scanner := bufio.NewScanner(Stdin(ctx))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// grepArgs grep 命令参数
+type grepArgs struct {
+	Pattern    string `arg:"regular expression"`
+	IgnoreCase bool   `arg:"ignore case distinctions" flag:"ignore-case,i"`
+	Invert     bool   `arg:"select non-matching lines" flag:"invert-match,v"`
+	Count      bool   `arg:"print only a count of matching lines" flag:"count,c"`
+}
+
+// newGrepCommand 创建 grep 命令, 输出匹配正则表达式的行
+// 没有匹配的行时返回 ErrNoMatch, 可以用于 && 以及 || 判断
+func newGrepCommand() *Command {
+	return NewCommandWithFuncE("grep", "print lines matching a pattern", func(ctx blocks.Context, arg *grepArgs) error {
+		pattern := arg.Pattern
+		if arg.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		count := 0
+		err = scanLines(ctx, func(line string) {
+			if re.MatchString(line) == arg.Invert {
+				return
+			}
+			count++
+			if !arg.Count {
+				ctx.Println(line)
+			}
+		})
+		if arg.Count {
+			ctx.Println(count)
+		}
+		if err == nil && count == 0 {
+			return ErrNoMatch
+		}
+		return err
+	})
+}
+
+// headArgs head 命令参数
+type headArgs struct {
+	Lines int `arg:"number of lines" flag:"lines,n" default:"10" check:"Range(0,)"`
+}
+
+// newHeadCommand 创建 head 命令, 输出前 n 行
+func newHeadCommand() *Command {
+	return NewCommandWithFuncE("head", "output the first part of input", func(ctx blocks.Context, arg *headArgs) error {
+		n := 0
+		return scanLines(ctx, func(line string) {
+			if n < arg.Lines {
+				ctx.Println(line)
+			}
+			n++
+		})
+	})
+}
+
+// wcArgs wc 命令参数
+type wcArgs struct {
+	Lines bool `arg:"print the line counts" flag:"lines,l"`
+	Words bool `arg:"print the word counts" flag:"words,w"`
+	Chars bool `arg:"print the character counts" flag:"chars,m"`
+	Bytes bool `arg:"print the byte counts" flag:"bytes,c"`
+}
+
+// newWcCommand 创建 wc 命令, 统计行数、单词数以及字节数
+// 未指定参数时输出行数、单词数以及字节数
+func newWcCommand() *Command {
+	return NewCommandWithFuncE("wc", "print line, word and byte counts", func(ctx blocks.Context, arg *wcArgs) error {
+		data, err := io.ReadAll(Stdin(ctx))
+		if err != nil {
+			return err
+		}
+		text := string(data)
+		if !arg.Lines && !arg.Words && !arg.Chars && !arg.Bytes {
+			arg.Lines, arg.Words, arg.Bytes = true, true, true
+		}
+		var counts []string
+		if arg.Lines {
+			counts = append(counts, fmt.Sprint(strings.Count(text, "\n")))
+		}
+		if arg.Words {
+			counts = append(counts, fmt.Sprint(len(strings.Fields(text))))
+		}
+		if arg.Chars {
+			counts = append(counts, fmt.Sprint(utf8.RuneCountInString(text)))
+		}
+		if arg.Bytes {
+			counts = append(counts, fmt.Sprint(len(data)))
+		}
+		ctx.Println(strings.Join(counts, " "))
+		return nil
+	})
+}
@@ -0,0 +1,172 @@
+package promptx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// CommandGroupEvent 命令组切换事件, 作为 onChange 回调的第二个参数
+// onChange 回调的参数为: 命令组名称, 事件, 命令组的状态
+type CommandGroupEvent int
+
+const (
+	// CommandGroupEnter 进入命令组（SwitchCommandGroup 或者 PushCommandGroup）
+	CommandGroupEnter CommandGroupEvent = iota
+	// CommandGroupLeave 离开通过 PushCommandGroup 进入的命令组
+	CommandGroupLeave
+)
+
+func (e CommandGroupEvent) String() string {
+	switch e {
+	case CommandGroupEnter:
+		return "enter"
+	case CommandGroupLeave:
+		return "leave"
+	default:
+		return fmt.Sprintf("CommandGroupEvent(%d)", int(e))
+	}
+}
+
+// defaultGroupTitle 默认命令组在导航路径中显示的名称
+const defaultGroupTitle = "main"
+
+// CommandGroupLevel 命令组导航栈中的一层
+type CommandGroupLevel struct {
+	// 命令组名称, 默认命令组为空字符串
+	Name string
+	// 进入命令组时传入的状态
+	State any
+}
+
+// CommandGroupNavigator 命令组导航接口
+// PushCommandGroup 进入子命令组, PopCommandGroup 返回上一层命令组
+type CommandGroupNavigator interface {
+	// PushCommandGroup 进入命令组, state 为该层的状态
+	PushCommandGroup(name string, state any) error
+	// PopCommandGroup 离开当前命令组, 返回上一层命令组
+	PopCommandGroup() error
+	// CommandGroupStack 返回导航栈, 第一个元素为最底层的命令组
+	CommandGroupStack() []CommandGroupLevel
+}
+
+// PushCommandGroup 进入命令组, 当前命令组保留在导航栈中
+// 通过接口判定，如果 ctx 实现了 CommandGroupNavigator 接口则调用，否则返回错误
+func PushCommandGroup(ctx blocks.Context, name string, state any) error {
+	nav, ok := ctx.(CommandGroupNavigator)
+	if !ok {
+		return fmt.Errorf("context does not implement CommandGroupNavigator interface")
+	}
+	return nav.PushCommandGroup(name, state)
+}
+
+// PopCommandGroup 返回上一层命令组
+// 通过接口判定，如果 ctx 实现了 CommandGroupNavigator 接口则调用，否则返回错误
+func PopCommandGroup(ctx blocks.Context) error {
+	nav, ok := ctx.(CommandGroupNavigator)
+	if !ok {
+		return fmt.Errorf("context does not implement CommandGroupNavigator interface")
+	}
+	return nav.PopCommandGroup()
+}
+
+// CommandGroupPath 返回导航栈中的命令组名称, 默认命令组显示为 main
+// 通过接口判定，如果 ctx 未实现 CommandGroupNavigator 接口则返回 nil
+func CommandGroupPath(ctx blocks.Context) []string {
+	nav, ok := ctx.(CommandGroupNavigator)
+	if !ok {
+		return nil
+	}
+	stack := nav.CommandGroupStack()
+	path := make([]string, 0, len(stack))
+	for _, level := range stack {
+		path = append(path, groupTitle(level.Name))
+	}
+	return path
+}
+
+// CommandGroupState 从栈顶开始查找类型为 T 的命令组状态
+// 子命令组中的命令可以读取上层命令组的状态
+func CommandGroupState[T any](ctx blocks.Context) (T, bool) {
+	var zero T
+	nav, ok := ctx.(CommandGroupNavigator)
+	if !ok {
+		return zero, false
+	}
+	stack := nav.CommandGroupStack()
+	for k := len(stack) - 1; k >= 0; k-- {
+		if state, ok := stack[k].State.(T); ok {
+			return state, true
+		}
+	}
+	return zero, false
+}
+
+// groupTitle 命令组在导航路径中显示的名称
+func groupTitle(name string) string {
+	if name == "" {
+		return defaultGroupTitle
+	}
+	return name
+}
+
+// groupLevel 导航栈中的一层（内部实现）
+type groupLevel struct {
+	name  string
+	group *Command
+	state any
+}
+
+// PushCommandGroup 进入命令组（实现 CommandGroupNavigator 接口）
+func (p *promptx) PushCommandGroup(name string, state any) error {
+	group, ok := p.groups[name]
+	if !ok {
+		return fmt.Errorf("command group %s not found", name)
+	}
+	level := &groupLevel{name: name, group: group, state: state}
+	p.stack = append(p.stack, level)
+	p.activateGroup(group)
+	p.fireGroupEvent(level, CommandGroupEnter)
+	return nil
+}
+
+// PopCommandGroup 返回上一层命令组（实现 CommandGroupNavigator 接口）
+func (p *promptx) PopCommandGroup() error {
+	if len(p.stack) < 2 {
+		return fmt.Errorf("no command group to pop")
+	}
+	level := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	p.activateGroup(p.stack[len(p.stack)-1].group)
+	p.fireGroupEvent(level, CommandGroupLeave)
+	return nil
+}
+
+// CommandGroupStack 返回导航栈（实现 CommandGroupNavigator 接口）
+func (p *promptx) CommandGroupStack() []CommandGroupLevel {
+	stack := make([]CommandGroupLevel, 0, len(p.stack))
+	for _, level := range p.stack {
+		stack = append(stack, CommandGroupLevel{Name: level.name, State: level.state})
+	}
+	return stack
+}
+
+// fireGroupEvent 调用命令组的切换回调
+func (p *promptx) fireGroupEvent(level *groupLevel, event CommandGroupEvent) {
+	if level.group.config != nil && level.group.config.onChange != nil {
+		level.group.config.onChange(p, level.name, event, level.state)
+	}
+}
+
+// groupPrompt 当前命令组的提示文字, 导航栈有多层时显示命令组路径, 例如: main > area1 >>>
+func (p *promptx) groupPrompt() string {
+	prompt := ""
+	if p.root.config != nil {
+		prompt = p.root.config.prompt
+	}
+	if len(p.stack) < 2 {
+		return prompt
+	}
+	return strings.Join(CommandGroupPath(p), " > ") + " " + prompt
+}
@@ -0,0 +1,150 @@
+package promptx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+type groupTestArea struct {
+	Name string
+}
+
+type groupTestRoom struct {
+	ID int
+}
+
+func TestCommandGroupNavigation(t *testing.T) {
+	var (
+		out    bytes.Buffer
+		events []string
+	)
+	onChange := func(ctx Context, args ...interface{}) {
+		events = append(events, fmt.Sprintf("%v %v %v", args...))
+	}
+	back := NewCommandWithFuncLegacy("back", "leave command group", func(ctx Context) {
+		if err := PopCommandGroup(ctx); err != nil {
+			ctx.Println(err)
+		}
+	})
+	show := NewCommandWithFuncLegacy("show", "show state", func(ctx Context) {
+		area, _ := CommandGroupState[groupTestArea](ctx)
+		room, ok := CommandGroupState[*groupTestRoom](ctx)
+		ctx.Println(CommandGroupPath(ctx), area.Name, ok && room.ID == 3)
+	})
+
+	cfg := NewConfig()
+	cfg.Hardware().
+		InputParser(testutil.NoTerminalParser{}).
+		OutputWriter(output.NewConsoleWriter(&out))
+	cfg.DefaultCommandGroup().CommandOnChange(onChange).AddCommand(
+		NewCommandWithFuncE("enter", "enter area", func(ctx Context, arg *scriptTestArgs) error {
+			return PushCommandGroup(ctx, "area1", groupTestArea{Name: arg.Name})
+		}),
+		show,
+	)
+	cfg.AddCommandGroup("area1").CommandOnChange(onChange).AddCommand(
+		NewCommandWithFuncLegacy("room", "enter room", func(ctx Context) {
+			PushCommandGroup(ctx, "room3", &groupTestRoom{ID: 3})
+		}),
+		NewCommandWithFuncLegacy("home", "switch to main", func(ctx Context) {
+			SwitchCommandGroup(ctx, "")
+		}),
+		back, show,
+	)
+	cfg.AddCommandGroup("room3").CommandOnChange(onChange).CommandPrompt("$").AddCommand(back, show)
+	p := cfg.Build()
+	px := p.(*promptx)
+
+	if err := p.RunArgs([]string{"enter north", "room", "show"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if prompt := px.groupPrompt(); prompt != "main > area1 > room3 $" {
+		t.Errorf("prompt should show command group path, but got %q", prompt)
+	}
+	if err := p.RunArgs([]string{"back", "show", "back", "show"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if prompt := px.groupPrompt(); prompt != ">>> " {
+		t.Errorf("prompt should be restored, but got %q", prompt)
+	}
+	if err := p.PopCommandGroup(); err == nil {
+		t.Errorf("pop the last command group should return error")
+	}
+	if err := p.RunArgs([]string{"enter south", "room"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := p.RunArgs([]string{"back", "home"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := "[main area1 room3] north true\n[main area1] north false\n[main]  false\n"
+	if out.String() != expected {
+		t.Errorf("output should be %q, but got %q", expected, out.String())
+	}
+	expectedEvents := []string{
+		"area1 enter {north}",
+		"room3 enter &{3}",
+		"room3 leave &{3}",
+		"area1 leave {north}",
+		"area1 enter {south}",
+		"room3 enter &{3}",
+		"room3 leave &{3}",
+		"area1 leave {south}",
+		" enter <nil>",
+	}
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("events should be %q, but got %q", expectedEvents, events)
+	}
+	if len(p.CommandGroupStack()) != 1 {
+		t.Errorf("SwitchCommandGroup should reset the stack, but got %v", p.CommandGroupStack())
+	}
+}
+
+func TestCommandGroupHistory(t *testing.T) {
+	newTest := func(dir string, shared bool) Promptx {
+		cfg := NewConfig()
+		cfg.Hardware().
+			InputParser(testutil.NoTerminalParser{}).
+			OutputWriter(output.NewConsoleWriter(&bytes.Buffer{}))
+		cfg.Common().SharedHistory(shared)
+		cfg.DefaultCommandGroup().CommandHistory(filepath.Join(dir, "main"))
+		cfg.AddCommandGroup("area1").CommandHistory(filepath.Join(dir, "area1"))
+		return cfg.Build()
+	}
+	readFile := func(name string) string {
+		data, _ := os.ReadFile(name)
+		return string(data)
+	}
+
+	dir := t.TempDir()
+	p := newTest(dir, false)
+	p.AddHistory("enter")
+	p.PushCommandGroup("area1", nil)
+	p.AddHistory("play")
+	p.PopCommandGroup()
+	p.AddHistory("status")
+	p.SwitchCommandGroup("area1")
+	if v := readFile(filepath.Join(dir, "main")); v != "enter\nstatus\n" {
+		t.Errorf("main history should be %q, but got %q", "enter\nstatus\n", v)
+	}
+	if v := readFile(filepath.Join(dir, "area1")); v != "play\n" {
+		t.Errorf("area1 history should be %q, but got %q", "play\n", v)
+	}
+
+	dir = t.TempDir()
+	p = newTest(dir, true)
+	p.AddHistory("enter")
+	p.PushCommandGroup("area1", nil)
+	p.AddHistory("play")
+	p.PopCommandGroup()
+	if _, err := os.Stat(filepath.Join(dir, "area1")); err == nil {
+		t.Errorf("shared history should not use command group history file")
+	}
+}
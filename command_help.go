@@ -0,0 +1,241 @@
+package promptx
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/completion"
+	"github.com/mattn/go-runewidth"
+)
+
+// helpArgs help 命令参数
+type helpArgs struct {
+	Command []string `arg:"command,optional"`
+}
+
+// newHelpCommand 创建 help 命令
+// root: 命令组的根命令
+func newHelpCommand(root *Command) *Command {
+	return NewCommandWithFuncE("help", "show help of commands", func(ctx blocks.Context, arg *helpArgs) error {
+		if len(arg.Command) == 0 {
+			ctx.Print(commandTreeHelp(root))
+			return nil
+		}
+		cmds, err := findCommandPath(root, arg.Command)
+		if err != nil {
+			return err
+		}
+		ctx.Print(commandHelp(cmds))
+		return nil
+	}).ArgComplete("Command", func(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+		// 根据已经输入的命令补全子命令
+		cur := root
+		if typed := args["Command"]; typed != "" {
+			cmds, err := findCommandPath(root, strings.Split(typed, ","))
+			if err != nil {
+				return nil
+			}
+			cur = cmds[len(cmds)-1]
+		}
+		var suggests []*completion.Suggest
+		for _, cmd := range cur.Children() {
+			if strings.HasPrefix(cmd.name, input) {
+				suggests = append(suggests, &completion.Suggest{Text: cmd.name, Description: cmd.help})
+			}
+		}
+		return suggests
+	})
+}
+
+// findCommandPath 根据名称查找命令链
+func findCommandPath(root *Command, names []string) ([]*Command, error) {
+	var cmds []*Command
+	cur := root
+	for _, name := range names {
+		cmd := cur.findChildCmd(name)
+		if cmd == nil {
+			return nil, fmt.Errorf("command %s not found", strings.Join(names, " "))
+		}
+		cmds = append(cmds, cmd)
+		cur = cmd
+	}
+	return cmds, nil
+}
+
+// Usage 返回命令的用法, 例如: login <server:dev|test|exp> <account> [email]
+func (c *Command) Usage() string {
+	return commandUsage([]*Command{c})
+}
+
+// commandUsage 返回命令链的用法, 包含父命令名称
+func commandUsage(cmds []*Command) string {
+	var parts []string
+	for _, cmd := range cmds {
+		parts = append(parts, cmd.name)
+	}
+	cur := cmds[len(cmds)-1]
+	if cur.hasSubcommand() && len(cur.argDefs) == 0 {
+		parts = append(parts, "<command>")
+	}
+	last := lastPositional(cur.argDefs)
+	for i, def := range cur.argDefs {
+		if !def.IsFlag() {
+			parts = append(parts, def.usage(i == last))
+		}
+	}
+	for _, def := range cur.argDefs {
+		if def.IsFlag() {
+			parts = append(parts, def.usage(false))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// usageName 参数在用法中显示的名称
+func (def *ArgDef) usageName() string {
+	if def.Flag != "" {
+		return def.Flag
+	}
+	var sb strings.Builder
+	for i, r := range def.Name {
+		if unicode.IsUpper(r) && i > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// usage 参数的用法. 必填参数使用 <> 包裹, 可选参数使用 [] 包裹
+// last: 是否是最后一个位置参数, slice/map 类型会接收剩余所有参数
+func (def *ArgDef) usage(last bool) string {
+	name := def.usageName()
+	if def.IsSelect && len(def.SelectOptions) > 0 && def.SelectFunc == nil && def.SelectFuncName == "" {
+		name += ":" + strings.Join(def.SelectOptions, "|")
+	}
+	if def.IsFlag() {
+		flag := def.FlagName()
+		if def.Flag != "" && def.Short != "" {
+			flag = "-" + def.Short + "|" + flag
+		}
+		if !def.IsBoolFlag() {
+			flag += " <" + name + ">"
+		}
+		if def.Required {
+			return flag
+		}
+		return "[" + flag + "]"
+	}
+	if last && isMultiValueType(def.Type) && !def.Multi {
+		name += "..."
+	}
+	if def.Required {
+		return "<" + name + ">"
+	}
+	return "[" + name + "]"
+}
+
+// commandTreeHelp 返回所有命令的帮助信息（树形结构）
+func commandTreeHelp(root *Command) string {
+	var rows [][2]string
+	var walk func(cmd *Command, depth int)
+	walk = func(cmd *Command, depth int) {
+		for _, child := range cmd.Children() {
+			rows = append(rows, [2]string{strings.Repeat("  ", depth) + child.Usage(), child.helpText()})
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+	return formatHelpRows("Commands:", rows)
+}
+
+// helpText 返回命令帮助信息, 包含别名
+func (c *Command) helpText() string {
+	if len(c.aliases) == 0 {
+		return c.help
+	}
+	return fmt.Sprintf("%s (alias: %s)", c.help, strings.Join(c.aliases, ", "))
+}
+
+// commandHelp 返回单个命令的详细帮助信息
+func commandHelp(cmds []*Command) string {
+	cur := cmds[len(cmds)-1]
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Usage: %s\n", commandUsage(cmds))
+	if cur.help != "" {
+		fmt.Fprintf(&sb, "  %s\n", cur.help)
+	}
+	if len(cur.aliases) > 0 {
+		fmt.Fprintf(&sb, "Aliases: %s\n", strings.Join(cur.aliases, ", "))
+	}
+	if len(cur.argDefs) > 0 {
+		var rows [][2]string
+		for _, def := range cur.argDefs {
+			name := def.usageName()
+			if def.IsFlag() {
+				name = def.FlagName()
+				if def.Flag != "" && def.Short != "" {
+					name = "-" + def.Short + ", " + name
+				}
+			}
+			rows = append(rows, [2]string{name, def.helpText()})
+		}
+		sb.WriteString(formatHelpRows("Arguments:", rows))
+	}
+	if cur.hasSubcommand() {
+		var rows [][2]string
+		for _, child := range cur.Children() {
+			rows = append(rows, [2]string{child.Usage(), child.helpText()})
+		}
+		sb.WriteString(formatHelpRows("Commands:", rows))
+	}
+	return sb.String()
+}
+
+// helpText 返回参数的说明, 包含默认值以及环境变量
+func (def *ArgDef) helpText() string {
+	text := def.Prompt
+	var extra []string
+	if !def.Required && !def.IsFlag() {
+		extra = append(extra, "optional")
+	}
+	if def.Default != "" {
+		extra = append(extra, "default: "+def.Default)
+	}
+	if def.Env != "" {
+		extra = append(extra, "env: "+def.Env)
+	}
+	if len(extra) > 0 {
+		text += " (" + strings.Join(extra, ", ") + ")"
+	}
+	return text
+}
+
+// helpColumnWidth 帮助信息第一列的最大宽度, 超过时说明在下一行输出
+const helpColumnWidth = 36
+
+// formatHelpRows 对齐输出帮助信息
+func formatHelpRows(title string, rows [][2]string) string {
+	width := 0
+	for _, row := range rows {
+		if w := runewidth.StringWidth(row[0]); w <= helpColumnWidth {
+			width = max(width, w)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(title)
+	sb.WriteByte('\n')
+	for _, row := range rows {
+		switch {
+		case row[1] == "":
+			fmt.Fprintf(&sb, "  %s\n", row[0])
+		case runewidth.StringWidth(row[0]) > width:
+			fmt.Fprintf(&sb, "  %s\n  %s  %s\n", row[0], strings.Repeat(" ", width), row[1])
+		default:
+			fmt.Fprintf(&sb, "  %s  %s\n", runewidth.FillRight(row[0], width), row[1])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package promptx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+type helpTestLoginArgs struct {
+	Server  string `arg:"server" select:"dev,test,exp"`
+	Account string `arg:"account"`
+	Email   string `arg:"email,optional"`
+	Port    int    `arg:"port" flag:"port,p" default:"22"`
+	Verbose bool   `arg:"verbose" flag:"verbose,v"`
+}
+
+type helpTestAddArgs struct {
+	Names []string `arg:"names"`
+}
+
+func TestCommandUsage(t *testing.T) {
+	login := NewCommandWithFunc("login", "login server", func(ctx Context, arg *helpTestLoginArgs) {}).Aliases("l")
+	if actual, expected := login.Usage(), "login <server:dev|test|exp> <account> [email] [-p|--port <port>] [-v|--verbose]"; actual != expected {
+		t.Errorf("usage should be %q, but got %q", expected, actual)
+	}
+
+	user := NewCommandWithFuncLegacy("user", "manage users", func(ctx Context) {}).SubCommands(
+		NewCommandWithFunc("add", "add users", func(ctx Context, arg *helpTestAddArgs) {}),
+	)
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(login, user, newHelpCommand(root))
+
+	tree := commandTreeHelp(root)
+	for _, line := range []string{
+		"  help [command...]  show help of commands\n",
+		// 过长的用法不参与对齐, 说明在下一行输出
+		"  login <server:dev|test|exp> <account> [email] [-p|--port <port>] [-v|--verbose]\n                     login server (alias: l)\n",
+		"  user <command>     manage users\n",
+		"    add <names...>   add users\n",
+	} {
+		if !strings.Contains(tree, line) {
+			t.Errorf("tree help should contain %q, but got:\n%s", line, tree)
+		}
+	}
+
+	cmds, err := findCommandPath(root, []string{"user", "add"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail := commandHelp(cmds); !strings.HasPrefix(detail, "Usage: user add <names...>\n") {
+		t.Errorf("command help should contain full path, but got:\n%s", detail)
+	}
+	cmds, _ = findCommandPath(root, []string{"l"})
+	detail := commandHelp(cmds)
+	for _, line := range []string{"Aliases: l", "email          email (optional)", "-p, --port     port (default: 22)"} {
+		if !strings.Contains(detail, line) {
+			t.Errorf("command help should contain %q, but got:\n%s", line, detail)
+		}
+	}
+	if _, err := findCommandPath(root, []string{"user", "del"}); err == nil {
+		t.Errorf("should return error for unknown command")
+	}
+
+	// 根据已经输入的命令补全子命令
+	var scenarioTable = []struct {
+		text     string
+		expected []string
+	}{
+		{text: "help ", expected: []string{"help", "login", "user"}},
+		{text: "help u", expected: []string{"user"}},
+		{text: "help user ", expected: []string{"add"}},
+		{text: "help user a", expected: []string{"add"}},
+		{text: "help login ", expected: nil},
+		{text: "help nope ", expected: nil},
+	}
+	for _, s := range scenarioTable {
+		var texts []string
+		for _, v := range root.FindSuggestWithContext(nil, *buffer.NewDocumentWithCursor(s.text, len(s.text))) {
+			texts = append(texts, v.Text)
+		}
+		if !reflect.DeepEqual(texts, s.expected) {
+			t.Errorf("%q: suggests should be %q, but got %q", s.text, s.expected, texts)
+		}
+	}
+}
+
+func TestHelpCommandStatus(t *testing.T) {
+	var out bytes.Buffer
+	cfg := NewConfig()
+	cfg.Hardware().
+		InputParser(testutil.NoTerminalParser{}).
+		OutputWriter(output.NewConsoleWriter(&out))
+	cfg.DefaultCommandGroup().HelpCommand().AddCommand(
+		NewCommandWithFunc("login", "login server", func(ctx Context, arg *helpTestLoginArgs) {}),
+	)
+	p := cfg.Build()
+
+	if status := p.RunOrExec([]string{"help", "login"}); status != StatusOK {
+		t.Errorf("status should be %d, but got %d", StatusOK, status)
+	}
+	// 未知命令返回错误
+	out.Reset()
+	if status := p.RunOrExec([]string{"help", "nope"}); status != StatusError {
+		t.Errorf("status should be %d, but got %d", StatusError, status)
+	}
+	if !strings.Contains(out.String(), "command nope not found") {
+		t.Errorf("should print error, but got %q", out.String())
+	}
+}
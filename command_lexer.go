@@ -0,0 +1,332 @@
+package promptx
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnterminatedQuote 引号未闭合
+	ErrUnterminatedQuote = errors.New("unterminated quoted string")
+	// ErrUnterminatedEscape 行尾存在未转义的反斜杠
+	ErrUnterminatedEscape = errors.New("unterminated backslash escape")
+)
+
+// token 命令行分词结果
+type token struct {
+	// 去除引号和转义后的值
+	Value string
+	// 原始文本（包含引号和转义符）
+	Raw string
+	// 在原始行中的起止位置（rune 下标，End 不包含）
+	Start int
+	End   int
+	// 未闭合的引号字符，0 表示引号已闭合
+	Quote rune
+	// 操作符（如 | > >>），为空表示普通参数
+	Op string
+}
+
+// 命令行操作符
+const (
+	// opPipe 管道, 前一个命令的输出作为后一个命令的输入
+	opPipe = "|"
+	// opRedirect 输出重定向到文件
+	opRedirect = ">"
+	// opAppend 输出追加到文件
+	opAppend = ">>"
+	// opSeq 依次执行命令
+	opSeq = ";"
+	// opAnd 前一个命令成功时执行
+	opAnd = "&&"
+	// opOr 前一个命令失败时执行
+	opOr = "||"
+)
+
+// commandOperators 引号外识别的操作符, 较长的操作符在前
+var commandOperators = []string{opAppend, opAnd, opOr, opPipe, opRedirect, opSeq}
+
+// matchOperator 匹配 runes[i:] 开头的操作符
+func matchOperator(runes []rune, i int) string {
+	for _, op := range commandOperators {
+		if strings.HasPrefix(string(runes[i:min(i+len(op), len(runes))]), op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// lexResult 分词结果
+type lexResult struct {
+	tokens []*token
+	// 行尾是否为空白（不在引号内）
+	trailingSpace bool
+	// 行尾是否存在未闭合的引号
+	openQuote rune
+	// 行尾是否存在未处理的反斜杠
+	openEscape bool
+}
+
+// err 返回分词错误
+func (r *lexResult) err() error {
+	if r.openQuote != 0 {
+		return ErrUnterminatedQuote
+	}
+	if r.openEscape {
+		return ErrUnterminatedEscape
+	}
+	return nil
+}
+
+// values 返回所有 token 的值
+func (r *lexResult) values() []string {
+	values := make([]string, 0, len(r.tokens))
+	for _, tok := range r.tokens {
+		values = append(values, tok.Value)
+	}
+	return values
+}
+
+// lexLine 按照 shell 的规则对命令行进行分词
+//
+// 支持的语法:
+//   - 空白字符分隔参数
+//   - 单引号: 内部所有字符按字面处理
+//   - 双引号: 内部允许使用反斜杠转义 " \ $ `
+//   - 反斜杠: 引号外转义下一个字符
+//   - 操作符: 引号外的 | > >> ; && || 作为单独的 token, 不需要使用空白分隔
+//
+// 分词不会因为错误中断，未闭合的引号会记录在结果中，由调用方决定如何处理。
+func lexLine(line string) *lexResult {
+	return lexLineVars(line, nil)
+}
+
+// lexLineVars 分词并展开变量
+// 引号外以及双引号内的 $name 、 ${name} 替换为 lookup 返回的值, 未定义的变量替换为空字符串.
+// 与 shell 一致, 引号外的变量展开为空并且参数中没有引号时丢弃该参数, "$name" 保留为空参数.
+// 展开后的值不会再拆分为多个参数, 也不会作为操作符. lookup 为 nil 时不展开变量.
+func lexLineVars(line string, lookup func(name string) (string, bool)) *lexResult {
+	var (
+		result  = &lexResult{}
+		runes   = []rune(line)
+		value   strings.Builder
+		cur     *token
+		quote   rune
+		escaped bool
+		// 当前参数中是否存在引号
+		quoted bool
+	)
+	begin := func(i int) {
+		if cur == nil {
+			cur = &token{Start: i}
+			value.Reset()
+			quoted = false
+		}
+	}
+	// expand 展开 runes[i:] 开头的变量, 返回变量占用的字符数, 0 表示不是变量
+	expand := func(i int) int {
+		if lookup == nil {
+			return 0
+		}
+		name, n := matchVariable(runes, i)
+		if n > 0 {
+			v, _ := lookup(name)
+			value.WriteString(v)
+		}
+		return n
+	}
+	finish := func(i int) {
+		if cur == nil {
+			return
+		}
+		// 只包含变量并且展开为空的参数
+		if lookup != nil && value.Len() == 0 && !quoted {
+			cur = nil
+			return
+		}
+		cur.Value = value.String()
+		cur.End = i
+		cur.Raw = string(runes[cur.Start:i])
+		result.tokens = append(result.tokens, cur)
+		cur = nil
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			escaped = false
+			// 双引号内只有特定字符可以转义
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				value.WriteRune('\\')
+			}
+			value.WriteRune(r)
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			value.WriteRune(r)
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			case '$':
+				if n := expand(i); n > 0 {
+					i += n - 1
+					continue
+				}
+				value.WriteRune(r)
+			default:
+				value.WriteRune(r)
+			}
+		case r == '\\':
+			begin(i)
+			escaped = true
+		case r == '\'' || r == '"':
+			begin(i)
+			quote, quoted = r, true
+		case r == '$' && lookup != nil && isVariable(runes, i):
+			begin(i)
+			i += expand(i) - 1
+		case unicode.IsSpace(r):
+			finish(i)
+		case matchOperator(runes, i) != "":
+			finish(i)
+			op := matchOperator(runes, i)
+			result.tokens = append(result.tokens, &token{
+				Value: op,
+				Raw:   op,
+				Start: i,
+				End:   i + len(op),
+				Op:    op,
+			})
+			i += len(op) - 1
+		default:
+			begin(i)
+			value.WriteRune(r)
+		}
+	}
+
+	result.openQuote = quote
+	result.openEscape = escaped
+	if cur != nil {
+		cur.Quote = quote
+		finish(len(runes))
+	} else if len(runes) > 0 {
+		result.trailingSpace = true
+	}
+	return result
+}
+
+// matchVariable 匹配 runes[i:] 开头的变量 $name 或者 ${name}
+// 返回变量名称以及占用的字符数, 不是变量时返回 0
+func matchVariable(runes []rune, i int) (name string, n int) {
+	if i+1 >= len(runes) || runes[i] != '$' {
+		return "", 0
+	}
+	if runes[i+1] == '{' {
+		end := i + 2
+		for end < len(runes) && isVariableRune(runes[end], end == i+2) {
+			end++
+		}
+		if end == i+2 || end >= len(runes) || runes[end] != '}' {
+			return "", 0
+		}
+		return string(runes[i+2 : end]), end - i + 1
+	}
+	end := i + 1
+	for end < len(runes) && isVariableRune(runes[end], end == i+1) {
+		end++
+	}
+	if end == i+1 {
+		return "", 0
+	}
+	return string(runes[i+1 : end]), end - i
+}
+
+// isVariable runes[i:] 开头是否为变量
+func isVariable(runes []rune, i int) bool {
+	_, n := matchVariable(runes, i)
+	return n > 0
+}
+
+// isVariableRune 字符是否可以作为变量名称, 变量名称由字母、数字、下划线组成, 不能以数字开头
+func isVariableRune(r rune, first bool) bool {
+	return r == '_' || (r < utf8.RuneSelf && unicode.IsLetter(r)) || (!first && r >= '0' && r <= '9')
+}
+
+// splitCommandLine 对命令行进行分词，引号未闭合时返回错误
+func splitCommandLine(line string) ([]string, error) {
+	result := lexLine(line)
+	if err := result.err(); err != nil {
+		return nil, err
+	}
+	return result.values(), nil
+}
+
+// quoteArg 在需要时为参数添加引号，保证 lexLine 可以还原出相同的值
+func quoteArg(value string) string {
+	if value == "" {
+		return `""`
+	}
+	if !strings.ContainsFunc(value, needQuote) {
+		return value
+	}
+	if !strings.ContainsRune(value, '\'') {
+		return "'" + value + "'"
+	}
+	return doubleQuote(value)
+}
+
+// doubleQuote 使用双引号包裹参数，并转义双引号内的特殊字符
+func doubleQuote(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		if strings.ContainsRune("\"\\$`", r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// needQuote 字符是否需要使用引号包裹
+func needQuote(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("'\"\\|>;&$", r)
+}
+
+// completeText 计算补全建议需要插入的文本
+//
+// 补全器会删除光标前最后一个分隔符之后的内容，再插入建议文本。
+// 当光标处于引号内或者参数包含空格时，需要只返回分隔符之后的部分，
+// 并补齐引号，保证替换后得到完整的参数。
+func completeText(partial *token, text string) string {
+	if partial == nil {
+		return quoteArg(text)
+	}
+	var final string
+	switch partial.Quote {
+	case '\'':
+		final = "'" + text + "'"
+	case '"':
+		final = doubleQuote(text)
+	default:
+		final = quoteArg(text)
+	}
+	// 已经输入的部分中, 最后一个空白之前的内容不会被补全器删除
+	kept := ""
+	if idx := strings.LastIndexFunc(partial.Raw, unicode.IsSpace); idx >= 0 {
+		kept = partial.Raw[:idx+1]
+	}
+	if !strings.HasPrefix(final, kept) {
+		return text
+	}
+	return final[len(kept):]
+}
@@ -0,0 +1,134 @@
+package promptx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/buffer"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	var scenarioTable = []struct {
+		scenario string
+		line     string
+		expected []string
+		err      error
+	}{
+		{
+			scenario: "plain words",
+			line:     "  login dev   alice ",
+			expected: []string{"login", "dev", "alice"},
+		},
+		{
+			scenario: "double quote",
+			line:     `login "my account" dev`,
+			expected: []string{"login", "my account", "dev"},
+		},
+		{
+			scenario: "single quote keep backslash",
+			line:     `echo 'a\b "c"'`,
+			expected: []string{"echo", `a\b "c"`},
+		},
+		{
+			scenario: "escape outside quote",
+			line:     `echo my\ account \"x\"`,
+			expected: []string{"echo", "my account", `"x"`},
+		},
+		{
+			scenario: "escape inside double quote",
+			line:     `echo "a\"b\\c\d"`,
+			expected: []string{"echo", `a"b\c\d`},
+		},
+		{
+			scenario: "empty quoted argument",
+			line:     `set name ""`,
+			expected: []string{"set", "name", ""},
+		},
+		{
+			scenario: "adjacent quoted parts",
+			line:     `echo ab"c d"'e'`,
+			expected: []string{"echo", "abc de"},
+		},
+		{
+			scenario: "operators",
+			line:     `list|grep dev>>out.txt`,
+			expected: []string{"list", "|", "grep", "dev", ">>", "out.txt"},
+		},
+		{
+			scenario: "chain operators",
+			line:     `build&&deploy||rollback;status & x`,
+			expected: []string{"build", "&&", "deploy", "||", "rollback", ";", "status", "&", "x"},
+		},
+		{
+			scenario: "quoted operators",
+			line:     `grep "a|b" \> '>>'`,
+			expected: []string{"grep", "a|b", ">", ">>"},
+		},
+		{
+			scenario: "unterminated quote",
+			line:     `login "my account`,
+			err:      ErrUnterminatedQuote,
+		},
+		{
+			scenario: "unterminated escape",
+			line:     `login dev\`,
+			err:      ErrUnterminatedEscape,
+		},
+	}
+	for _, s := range scenarioTable {
+		actual, err := splitCommandLine(s.line)
+		if err != s.err {
+			t.Errorf("%s: error should be %v, but got %v", s.scenario, s.err, err)
+			continue
+		}
+		if s.err == nil && !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%s: should be %#v, but got %#v", s.scenario, s.expected, actual)
+		}
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, v := range []string{"", "abc", "my account", `it's`, `a "b" $c`, `back\slash`} {
+		actual, err := splitCommandLine(quoteArg(v))
+		if err != nil {
+			t.Errorf("quote %q failed: %v", v, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, []string{v}) {
+			t.Errorf("quote %q should round trip, but got %#v", v, actual)
+		}
+	}
+}
+
+func TestFindSuggestInQuote(t *testing.T) {
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(
+		NewCommandWithFuncLegacy("login", "login server", func(ctx Context) {}),
+		NewCommandWithFuncLegacy("logout", "logout server", func(ctx Context) {}),
+	)
+
+	var scenarioTable = []struct {
+		line     string
+		expected []string
+	}{
+		{line: "", expected: []string{"login", "logout"}},
+		{line: "logi", expected: []string{"login"}},
+		{line: `"logi`, expected: []string{`"login"`}},
+		{line: `'lo`, expected: []string{`'login'`, `'logout'`}},
+		{line: "login ", expected: nil},
+	}
+	for _, s := range scenarioTable {
+		var actual []string
+		for _, v := range root.FindSuggest(*buffer.NewDocumentWithCursor(s.line, len([]rune(s.line)))) {
+			actual = append(actual, v.Text)
+		}
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %#v, but got %#v", s.line, s.expected, actual)
+		}
+	}
+
+	partial := lexLine(`login "my ac`).tokens[1]
+	if actual := completeText(partial, "my account"); actual != `account"` {
+		t.Errorf("should be %q, but got %q", `account"`, actual)
+	}
+}
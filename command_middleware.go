@@ -0,0 +1,78 @@
+package promptx
+
+import (
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// Invocation 命令调用信息
+type Invocation struct {
+	// 命令链, 从顶层命令到执行的命令
+	Commands []*Command
+	// 解析后的参数. 泛型命令为 ARG 结构体指针, 自定义命令为 Commander, 无参数时为 nil
+	// 注意: 调用 ParseArg 或者 next 之前 Arg 始终为 nil. 中间件在调用 next 之前需要参数时先调用 ParseArg
+	Arg any
+	// 原始参数（不包含命令名称）
+	Args []string
+	// 原始输入行（不包含命令前缀）
+	Line string
+	// 是否已经解析参数
+	parsed bool
+}
+
+// Command 返回执行的命令
+func (inv *Invocation) Command() *Command {
+	return inv.Commands[len(inv.Commands)-1]
+}
+
+// ParseArg 检查并解析参数, 结果保存到 Arg, 多次调用只解析一次
+// 缺少参数时可能交互输入, 检查失败返回 *UsageError
+func (inv *Invocation) ParseArg(ctx blocks.Context) error {
+	if inv.parsed {
+		return nil
+	}
+	arg, err := parseCommandArg(ctx, inv.Commands, inv.Args)
+	if err != nil {
+		return err
+	}
+	inv.Arg, inv.parsed = arg, true
+	return nil
+}
+
+// Handler 命令处理函数
+type Handler func(ctx blocks.Context, inv *Invocation) error
+
+// Middleware 命令中间件, 包装命令的执行. 可以用于权限检查、计时、审计日志、panic 转换等
+// 中间件在检查参数之前执行, 可以在交互输入缺少的参数以及打印用法之前拒绝执行.
+// inv.Arg 在调用 next 之后才有值, 需要在调用 next 之前读取参数时先调用 inv.ParseArg
+type Middleware func(next Handler) Handler
+
+// Use 添加命令中间件
+// 中间件对命令以及命令的所有子命令生效, 父命令的中间件在外层, 按照添加的顺序执行
+func (c *Command) Use(mw ...Middleware) *Command {
+	c.middlewares = append(c.middlewares, mw...)
+	return c
+}
+
+// execHandler 解析参数并执行命令的 Handler
+func execHandler(ctx blocks.Context, inv *Invocation) error {
+	if err := inv.ParseArg(ctx); err != nil {
+		return err
+	}
+	return inv.Command().Exec(ctx, inv.Arg)
+}
+
+// chainMiddlewares 使用命令组以及命令链上的中间件包装 Handler
+// 命令组的中间件在最外层, 之后依次为父命令、子命令的中间件
+func chainMiddlewares(root *Command, cmds []*Command, handler Handler) Handler {
+	var mws []Middleware
+	if root != nil && root.config != nil {
+		mws = append(mws, root.config.middlewares...)
+	}
+	for _, cmd := range cmds {
+		mws = append(mws, cmd.middlewares...)
+	}
+	for k := len(mws) - 1; k >= 0; k-- {
+		handler = mws[k](handler)
+	}
+	return handler
+}
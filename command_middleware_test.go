@@ -0,0 +1,149 @@
+package promptx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type middlewareTestArgs struct {
+	Name string `arg:"name"`
+}
+
+func TestMiddleware(t *testing.T) {
+	var trace []string
+	record := func(tag string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx Context, inv *Invocation) error {
+				trace = append(trace, tag+":"+inv.Command().Name())
+				return next(ctx, inv)
+			}
+		}
+	}
+	errDenied := errors.New("denied")
+
+	add := NewCommandWithFunc("add", "add user", func(ctx Context, arg *middlewareTestArgs) {
+		trace = append(trace, "exec:"+arg.Name)
+	}).Use(record("add"))
+	del := NewCommandWithFuncLegacy("del", "delete user", func(ctx Context) {
+		panic("boom")
+	}).Use(func(next Handler) Handler {
+		return func(ctx Context, inv *Invocation) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("translated: %v", p)
+				}
+			}()
+			return next(ctx, inv)
+		}
+	})
+	user := NewCommandWithFuncLegacy("user", "manage users", func(ctx Context) {}).
+		SubCommands(add, del).
+		Use(record("user"))
+
+	root := &Command{config: newRootCommandConfig()}
+	root.config.middlewares = append(root.config.middlewares, record("group"), func(next Handler) Handler {
+		return func(ctx Context, inv *Invocation) error {
+			// 未登录时在检查参数之前拒绝
+			if inv.Command().Name() == "add" && len(inv.Args) == 0 {
+				return errDenied
+			}
+			if err := inv.ParseArg(ctx); err != nil {
+				return err
+			}
+			if arg, ok := inv.Arg.(*middlewareTestArgs); ok && arg.Name == "root" {
+				return errDenied
+			}
+			return next(ctx, inv)
+		}
+	})
+	root.SubCommands(user)
+
+	if _, err := execCommand(nil, root, "user add alice"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"group:add", "user:add", "add:add", "exec:alice"}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Errorf("should be %#v, but got %#v", expected, trace)
+	}
+
+	trace = nil
+	if _, err := execCommand(nil, root, "user add root"); !errors.Is(err, errDenied) {
+		t.Errorf("middleware should stop command, but got %v", err)
+	}
+	if !reflect.DeepEqual(trace, []string{"group:add"}) {
+		t.Errorf("command should not run, but got %#v", trace)
+	}
+
+	// 中间件在交互输入以及打印用法之前执行
+	trace = nil
+	if _, err := execCommand(nil, root, "user add"); !errors.Is(err, errDenied) {
+		t.Errorf("middleware should run before checking args, but got %v", err)
+	}
+	if !reflect.DeepEqual(trace, []string{"group:add"}) {
+		t.Errorf("command should not run, but got %#v", trace)
+	}
+
+	if _, err := execCommand(nil, root, "user del"); err == nil || err.Error() != "translated: boom" {
+		t.Errorf("middleware should translate panic, but got %v", err)
+	}
+
+	var line string
+	var args []string
+	root.config.middlewares = []Middleware{func(next Handler) Handler {
+		return func(ctx Context, inv *Invocation) error {
+			line, args = inv.Line, inv.Args
+			return next(ctx, inv)
+		}
+	}}
+	execCommand(nil, root, "user  add 'bob'")
+	if line != "user  add 'bob'" {
+		t.Errorf("should receive raw line, but got %q", line)
+	}
+	if !reflect.DeepEqual(args, []string{"bob"}) {
+		t.Errorf("should receive raw args, but got %q", args)
+	}
+}
+
+func TestMiddlewareArg(t *testing.T) {
+	var before, after, parsed any
+	add := NewCommandWithFunc("add", "add user", func(ctx Context, arg *middlewareTestArgs) {}).
+		Use(func(next Handler) Handler {
+			return func(ctx Context, inv *Invocation) error {
+				// 调用 next 之前参数尚未解析
+				before = inv.Arg
+				err := next(ctx, inv)
+				after = inv.Arg
+				return err
+			}
+		})
+	audit := NewCommandWithFunc("audit", "audit user", func(ctx Context, arg *middlewareTestArgs) {}).
+		Use(func(next Handler) Handler {
+			return func(ctx Context, inv *Invocation) error {
+				if err := inv.ParseArg(ctx); err != nil {
+					return err
+				}
+				parsed = inv.Arg
+				return next(ctx, inv)
+			}
+		})
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(add, audit)
+
+	if _, err := execCommand(nil, root, "add alice"); err != nil {
+		t.Fatal(err)
+	}
+	if before != nil {
+		t.Errorf("arg should be nil before next, but got %#v", before)
+	}
+	if arg, ok := after.(*middlewareTestArgs); !ok || arg.Name != "alice" {
+		t.Errorf("arg should be parsed after next, but got %#v", after)
+	}
+	if _, err := execCommand(nil, root, "audit bob"); err != nil {
+		t.Fatal(err)
+	}
+	if arg, ok := parsed.(*middlewareTestArgs); !ok || arg.Name != "bob" {
+		t.Errorf("ParseArg should parse arg before next, but got %#v", parsed)
+	}
+}
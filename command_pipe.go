@@ -0,0 +1,178 @@
+package promptx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// pipeline 管道命令, 例如: list | grep dev > out.txt
+type pipeline struct {
+	// 管道中的命令, 前一个命令的输出作为后一个命令的输入
+	stages []*commandContext
+	// 输出重定向的文件, 为空表示输出到终端
+	redirect string
+	// 是否追加到文件
+	appendFile bool
+}
+
+// parsePipeline 解析管道命令
+// 第一个命令之后的命令在命令组中找不到时, 使用内置的过滤命令（grep/head/wc）
+func parsePipeline(root *Command, line string) (*pipeline, error) {
+	result := lexLine(line)
+	if err := result.err(); err != nil {
+		return nil, err
+	}
+	return parsePipelineTokens(root, []rune(line), result.tokens)
+}
+
+// parsePipelineTokens 从分词结果中解析管道命令
+// runes 为完整的输入行, 用于截取每个命令的原始输入
+func parsePipelineTokens(root *Command, runes []rune, tokens []*token) (*pipeline, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var (
+		pl       = &pipeline{}
+		fields   []string
+		start    = tokens[0].Start
+		redirect string
+	)
+	addStage := func(end int, op string) error {
+		if len(fields) == 0 {
+			return fmt.Errorf("syntax error near %s", op)
+		}
+		stageLine := strings.TrimSpace(string(runes[start:end]))
+		cmdCtx := parseCommandFields(root, fields, stageLine)
+		if len(pl.stages) > 0 && !cmdCtx.found() {
+			// 使用内置的过滤命令, 中间件等仍然使用命令组的配置
+			if filter := parseCommandFields(filterCommands(), fields, stageLine); filter.found() {
+				filter.root = root
+				cmdCtx = filter
+			}
+		}
+		pl.stages = append(pl.stages, cmdCtx)
+		fields = nil
+		return nil
+	}
+	for _, tok := range tokens {
+		switch {
+		case redirect != "":
+			// 重定向的文件名
+			if tok.Op != "" {
+				return nil, fmt.Errorf("syntax error near %s", tok.Op)
+			}
+			pl.redirect, pl.appendFile = tok.Value, redirect == opAppend
+			redirect = ""
+		case pl.redirect != "":
+			return nil, fmt.Errorf("unexpected %s after redirect", tok.Raw)
+		case tok.Op == opPipe:
+			if err := addStage(tok.Start, tok.Op); err != nil {
+				return nil, err
+			}
+			start = tok.End
+		case tok.Op == opRedirect || tok.Op == opAppend:
+			if err := addStage(tok.Start, tok.Op); err != nil {
+				return nil, err
+			}
+			redirect = tok.Op
+		case tok.Op != "":
+			return nil, fmt.Errorf("syntax error near %s", tok.Op)
+		default:
+			fields = append(fields, tok.Value)
+		}
+	}
+	if redirect != "" {
+		return nil, fmt.Errorf("missing file name after %s", redirect)
+	}
+	if pl.redirect == "" {
+		if err := addStage(tokens[len(tokens)-1].End, opPipe); err != nil {
+			return nil, err
+		}
+	}
+	return pl, nil
+}
+
+// found 是否找到了第一个命令
+func (pl *pipeline) found() bool {
+	return pl != nil && pl.stages[0].found()
+}
+
+// check 检查管道中的命令都存在
+func (pl *pipeline) check() error {
+	for _, stage := range pl.stages {
+		if !stage.found() {
+			return fmt.Errorf("%w: %s", ErrCommandNotFound, stage.line)
+		}
+	}
+	return nil
+}
+
+// run 依次执行管道中的命令
+// newCtx 创建命令执行的上下文, in 为命令的输入, out 为命令的输出（nil 表示终端）
+func (pl *pipeline) run(taskCtx context.Context, newCtx func(in io.Reader, out io.Writer) blocks.Context) (err error) {
+	if err = pl.check(); err != nil {
+		return err
+	}
+	var out io.Writer
+	if pl.redirect != "" {
+		flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if pl.appendFile {
+			flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		f, err := os.OpenFile(pl.redirect, flag, 0o644)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		out = f
+	}
+
+	var in io.Reader
+	for k, stage := range pl.stages {
+		if err := taskCtx.Err(); err != nil {
+			return err
+		}
+		stageOut := out
+		var buf *bytes.Buffer
+		if k < len(pl.stages)-1 {
+			buf = new(bytes.Buffer)
+			stageOut = buf
+		}
+		// 管道中间的 grep 没有匹配时继续执行, 管道的执行状态为最后一个命令的状态
+		err := runCommand(newCtx(in, stageOut), stage)
+		if err != nil && (buf == nil || !errors.Is(err, ErrNoMatch)) {
+			return err
+		}
+		in = buf
+	}
+	return nil
+}
+
+// StdinReader 命令输入接口
+type StdinReader interface {
+	// Stdin 返回命令的输入
+	Stdin() io.Reader
+}
+
+// Stdin 返回命令的输入
+// 管道中的命令读取前一个命令的输出, 其他情况返回空的 Reader
+func Stdin(ctx blocks.Context) io.Reader {
+	if reader, ok := ctx.(StdinReader); ok {
+		if in := reader.Stdin(); in != nil {
+			return in
+		}
+	}
+	return strings.NewReader("")
+}
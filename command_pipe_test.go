@@ -0,0 +1,219 @@
+package promptx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/buffer"
+)
+
+// pipeTestContext 测试使用的命令上下文, 输出写入 out
+type pipeTestContext struct {
+	blocks.Context
+	in  io.Reader
+	out io.Writer
+}
+
+func (c *pipeTestContext) Stdin() io.Reader  { return c.in }
+func (c *pipeTestContext) Stdout() io.Writer { return c.out }
+func (c *pipeTestContext) Print(v ...interface{}) {
+	fmt.Fprint(c.out, v...)
+}
+func (c *pipeTestContext) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(c.out, format, v...)
+}
+func (c *pipeTestContext) Println(v ...interface{}) {
+	fmt.Fprintln(c.out, v...)
+}
+
+func newPipeTestRoot() *Command {
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(
+		NewCommandWithFuncLegacy("list", "list servers", func(ctx Context) {
+			ctx.Println("dev-1")
+			ctx.Println("test-1")
+			ctx.Println("dev-2")
+		}),
+		NewCommandWithFuncE("fail", "always fail", func(ctx Context, arg *statusTestArgs) error {
+			return errors.New("failed")
+		}),
+	)
+	return root
+}
+
+// runPipeTest 执行管道命令, 返回终端输出
+func runPipeTest(root *Command, line string) (string, error) {
+	pl, err := parsePipeline(root, line)
+	if err != nil {
+		return "", err
+	}
+	if !pl.found() {
+		return "", ErrCommandNotFound
+	}
+	var terminal bytes.Buffer
+	err = pl.run(context.Background(), func(in io.Reader, out io.Writer) blocks.Context {
+		if out == nil {
+			out = &terminal
+		}
+		return &pipeTestContext{in: in, out: out}
+	})
+	return terminal.String(), err
+}
+
+func TestParsePipeline(t *testing.T) {
+	root := newPipeTestRoot()
+	var scenarioTable = []struct {
+		line     string
+		stages   []string
+		redirect string
+		append   bool
+		err      string
+	}{
+		{line: "list", stages: []string{"list"}},
+		{line: "list | grep dev", stages: []string{"list", "grep dev"}},
+		{line: "list|grep dev|head -n 1", stages: []string{"list", "grep dev", "head -n 1"}},
+		{line: "list > out.txt", stages: []string{"list"}, redirect: "out.txt"},
+		{line: "list | wc >> 'my file'", stages: []string{"list", "wc"}, redirect: "my file", append: true},
+		{line: "| grep dev", err: "syntax error near |"},
+		{line: "list | | grep", err: "syntax error near |"},
+		{line: "list | ", err: "syntax error near |"},
+		{line: "list >", err: "missing file name after >"},
+		{line: "list > a > b", err: "unexpected > after redirect"},
+		{line: "list > a | grep", err: "unexpected | after redirect"},
+	}
+	for _, s := range scenarioTable {
+		pl, err := parsePipeline(root, s.line)
+		if s.err != "" {
+			if err == nil || err.Error() != s.err {
+				t.Errorf("%q: error should be %q, but got %v", s.line, s.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", s.line, err)
+			continue
+		}
+		var stages []string
+		for _, stage := range pl.stages {
+			stages = append(stages, stage.line)
+		}
+		if strings.Join(stages, ";") != strings.Join(s.stages, ";") {
+			t.Errorf("%q: stages should be %q, but got %q", s.line, s.stages, stages)
+		}
+		if pl.redirect != s.redirect || pl.appendFile != s.append {
+			t.Errorf("%q: redirect should be %q/%v, but got %q/%v", s.line, s.redirect, s.append, pl.redirect, pl.appendFile)
+		}
+	}
+}
+
+func TestRunPipeline(t *testing.T) {
+	root := newPipeTestRoot()
+	var scenarioTable = []struct {
+		line     string
+		expected string
+	}{
+		{line: "list", expected: "dev-1\ntest-1\ndev-2\n"},
+		{line: "list | grep dev", expected: "dev-1\ndev-2\n"},
+		{line: "list | grep -v dev", expected: "test-1\n"},
+		{line: "list | grep -i DEV | head -n 1", expected: "dev-1\n"},
+		{line: "list | grep -c dev", expected: "2\n"},
+		{line: "list | head", expected: "dev-1\ntest-1\ndev-2\n"},
+		{line: "list | wc", expected: "3 3 19\n"},
+		{line: "list | wc -l", expected: "3\n"},
+	}
+	for _, s := range scenarioTable {
+		actual, err := runPipeTest(root, s.line)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", s.line, err)
+			continue
+		}
+		if actual != s.expected {
+			t.Errorf("%q: output should be %q, but got %q", s.line, s.expected, actual)
+		}
+	}
+
+	if _, err := runPipeTest(root, "list | unknown"); !errors.Is(err, ErrCommandNotFound) {
+		t.Errorf("unknown stage should return ErrCommandNotFound, but got %v", err)
+	}
+	if _, err := runPipeTest(root, "fail 1 | grep x"); err == nil || err.Error() != "failed" {
+		t.Errorf("failed stage should stop pipeline, but got %v", err)
+	}
+	if _, err := runPipeTest(root, "list | grep ("); err == nil {
+		t.Errorf("invalid pattern should return error")
+	}
+}
+
+func TestPipelineRedirect(t *testing.T) {
+	root := newPipeTestRoot()
+	file := filepath.Join(t.TempDir(), "out.txt")
+
+	out, err := runPipeTest(root, "list | grep dev > "+quoteArg(file))
+	if err != nil || out != "" {
+		t.Fatalf("redirect should not print to terminal, output %q, error %v", out, err)
+	}
+	if _, err = runPipeTest(root, "list | grep test >> "+quoteArg(file)); err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != "dev-1\ndev-2\ntest-1\n" {
+		t.Errorf("file content should be appended, but got %q", data)
+	}
+
+	if _, err = runPipeTest(root, "list > "+quoteArg(file)); err != nil {
+		t.Fatalf("truncate failed: %v", err)
+	}
+	data, _ = os.ReadFile(file)
+	if string(data) != "dev-1\ntest-1\ndev-2\n" {
+		t.Errorf("file content should be truncated, but got %q", data)
+	}
+}
+
+func TestPipelineSuggest(t *testing.T) {
+	root := newPipeTestRoot()
+	var scenarioTable = []struct {
+		text     string
+		expected []string
+		applied  string
+	}{
+		{text: "list | gr", expected: []string{"grep"}},
+		{text: "list |he", expected: []string{"|head"}, applied: "list |head"},
+		{text: "list | li", expected: []string{"list"}},
+		{text: "list | head --l", expected: []string{"--lines"}},
+		{text: "list && fa", expected: []string{"fail"}},
+		// 操作符前后没有空格时保留操作符以及之前的内容
+		{text: "fail 1;li", expected: []string{"1;list"}, applied: "fail 1;list"},
+		{text: "fail a|he", expected: []string{"a|head"}, applied: "fail a|head"},
+		{text: "fail a|", expected: []string{"a|fail", "a|list", "a|grep", "a|head", "a|wc"}, applied: "fail a|fail"},
+		{text: "fail a>outp", expected: []string{"a>output/"}, applied: "fail a>output/"},
+		{text: "fail 'x y'|he", expected: []string{"y'|head"}, applied: "fail 'x y'|head"},
+	}
+	for _, s := range scenarioTable {
+		suggests := root.FindSuggest(*buffer.NewDocumentWithCursor(s.text, len(s.text)))
+		var texts []string
+		for _, v := range suggests {
+			texts = append(texts, v.Text)
+		}
+		if strings.Join(texts, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("%q: suggests should be %v, but got %v", s.text, s.expected, texts)
+		}
+		if s.applied == "" || len(suggests) == 0 {
+			continue
+		}
+		// 与补全器相同, 删除最后一个空格之后的内容再插入建议
+		buf := buffer.NewBuffer()
+		buf.InsertText(s.text, false, true)
+		buf.DeleteBeforeCursor(len([]rune(buf.Document().GetWordBeforeCursorUntilSeparator(" "))))
+		buf.InsertText(suggests[0].Text, false, true)
+		if buf.Text() != s.applied {
+			t.Errorf("%q: applied text should be %q, but got %q", s.text, s.applied, buf.Text())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package promptx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// 命令执行状态, 与 shell 的 $? 含义相同
+const (
+	// StatusOK 执行成功
+	StatusOK = 0
+	// StatusError 命令执行失败
+	StatusError = 1
+	// StatusUsage 参数错误
+	StatusUsage = 2
+	// StatusNotFound 命令不存在
+	StatusNotFound = 127
+	// StatusInterrupted 命令被 Ctrl-C 取消
+	StatusInterrupted = 130
+)
+
+// ErrCommandNotFound 命令不存在
+var ErrCommandNotFound = errors.New("command not found")
+
+// UsageError 参数检查失败的错误, 包含命令的用法
+type UsageError struct {
+	// 命令用法
+	Usage string
+	// 参数检查的错误
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// CommandStatus 命令执行状态接口
+type CommandStatus interface {
+	// LastStatus 返回上一个命令的执行状态, 0 表示成功
+	LastStatus() int
+	// LastError 返回上一个命令的错误, 成功时为 nil
+	LastError() error
+}
+
+// LastStatus 返回上一个命令的执行状态
+// 通过接口判定，如果 ctx 未实现 CommandStatus 接口则返回 StatusOK
+func LastStatus(ctx blocks.Context) int {
+	status, ok := ctx.(CommandStatus)
+	if !ok {
+		return StatusOK
+	}
+	return status.LastStatus()
+}
+
+// LastError 返回上一个命令的错误
+// 通过接口判定，如果 ctx 未实现 CommandStatus 接口则返回 nil
+func LastError(ctx blocks.Context) error {
+	status, ok := ctx.(CommandStatus)
+	if !ok {
+		return nil
+	}
+	return status.LastError()
+}
+
+// statusOf 根据错误返回命令执行状态
+func statusOf(err error) int {
+	var usageErr *UsageError
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, ErrCommandNotFound):
+		return StatusNotFound
+	case errors.Is(err, context.Canceled):
+		return StatusInterrupted
+	case errors.As(err, &usageErr):
+		return StatusUsage
+	default:
+		return StatusError
+	}
+}
+
+// printCommandError 默认的命令错误输出
+func printCommandError(ctx blocks.Context, line string, err error) {
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		ctx.Printf("参数检查失败: %v\n", usageErr.Err)
+		if !errors.Is(usageErr.Err, errUserCancel) {
+			ctx.Printf("usage: %s\n", usageErr.Usage)
+		}
+		return
+	}
+	if errors.Is(err, ErrUnterminatedQuote) || errors.Is(err, ErrUnterminatedEscape) {
+		ctx.Printf("解析命令失败: %v\n", err)
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		ctx.Printf("命令已取消\n")
+		return
+	}
+	if errors.Is(err, ErrCommandNotFound) {
+		ctx.Printf("%v\n", err)
+		return
+	}
+	if errors.Is(err, ErrNoMatch) {
+		return
+	}
+	ctx.Printf("执行命令失败: %v\n", err)
+}
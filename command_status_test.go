@@ -0,0 +1,86 @@
+package promptx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type statusTestArgs struct {
+	Count int `arg:"count"`
+}
+
+type statusTestCommander struct {
+	Fail bool `arg:"fail" flag:"fail"`
+}
+
+func (c *statusTestCommander) Name() string { return "commander" }
+func (c *statusTestCommander) Help() string { return "commander returns error" }
+func (c *statusTestCommander) Exec(ctx Context) error {
+	if c.Fail {
+		return errors.New("commander failed")
+	}
+	return nil
+}
+
+func TestExecCommandStatus(t *testing.T) {
+	errFailed := errors.New("failed")
+	root := &Command{config: newRootCommandConfig()}
+	root.SubCommands(
+		NewCommandWithFuncE("run", "run", func(ctx Context, arg *statusTestArgs) error {
+			if arg.Count < 0 {
+				return errFailed
+			}
+			return nil
+		}),
+		NewCommandWithFuncLegacy("crash", "crash", func(ctx Context) {
+			panic("boom")
+		}),
+		NewCommandE(&statusTestCommander{}),
+	)
+
+	var scenarioTable = []struct {
+		line   string
+		find   bool
+		status int
+	}{
+		{line: "run 1", find: true, status: StatusOK},
+		{line: "run -1", find: true, status: StatusError},
+		{line: "run abc", find: true, status: StatusUsage},
+		{line: "crash", find: true, status: StatusError},
+		{line: "commander", find: true, status: StatusOK},
+		{line: "commander --fail", find: true, status: StatusError},
+		{line: "unknown", find: false, status: StatusOK},
+		{line: `run "1`, find: false, status: StatusError},
+	}
+	for _, s := range scenarioTable {
+		find, err := execCommand(nil, root, s.line)
+		if find != s.find {
+			t.Errorf("%q: find should be %v, but got %v", s.line, s.find, find)
+		}
+		if status := statusOf(err); status != s.status {
+			t.Errorf("%q: status should be %d, but got %d (%v)", s.line, s.status, status, err)
+		}
+	}
+
+	_, err := execCommand(nil, root, "run -1")
+	if !errors.Is(err, errFailed) {
+		t.Errorf("should return command error, but got %v", err)
+	}
+	_, err = execCommand(nil, root, "run abc")
+	var usageErr *UsageError
+	if !errors.As(err, &usageErr) || usageErr.Usage != "run <count>" {
+		t.Errorf("should return usage error, but got %#v", err)
+	}
+	_, err = execCommand(nil, root, "crash")
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: boom") {
+		t.Errorf("should convert panic to error, but got %v", err)
+	}
+	if statusOf(context.Canceled) != StatusInterrupted {
+		t.Errorf("canceled command status should be %d", StatusInterrupted)
+	}
+	if statusOf(ErrCommandNotFound) != StatusNotFound {
+		t.Errorf("command not found status should be %d", StatusNotFound)
+	}
+}
@@ -0,0 +1,214 @@
+package promptx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/completion"
+)
+
+// VariableStore 会话变量接口
+// 命令行中的 $name 、 ${name} 在解析参数之前替换为变量的值
+type VariableStore interface {
+	// GetVar 返回变量的值
+	GetVar(name string) (string, bool)
+	// SetVar 设置变量
+	SetVar(name, value string) error
+	// UnsetVar 删除变量
+	UnsetVar(name string) error
+	// Vars 返回所有变量
+	Vars() map[string]string
+}
+
+// GetVar 返回变量的值
+// 通过接口判定，如果 ctx 未实现 VariableStore 接口则返回 false
+func GetVar(ctx blocks.Context, name string) (string, bool) {
+	store, ok := ctx.(VariableStore)
+	if !ok {
+		return "", false
+	}
+	return store.GetVar(name)
+}
+
+// SetVar 设置变量
+// 通过接口判定，如果 ctx 实现了 VariableStore 接口则调用，否则返回错误
+func SetVar(ctx blocks.Context, name, value string) error {
+	store, ok := ctx.(VariableStore)
+	if !ok {
+		return fmt.Errorf("context does not implement VariableStore interface")
+	}
+	return store.SetVar(name, value)
+}
+
+// UnsetVar 删除变量
+// 通过接口判定，如果 ctx 实现了 VariableStore 接口则调用，否则返回错误
+func UnsetVar(ctx blocks.Context, name string) error {
+	store, ok := ctx.(VariableStore)
+	if !ok {
+		return fmt.Errorf("context does not implement VariableStore interface")
+	}
+	return store.UnsetVar(name)
+}
+
+// varsOf 返回 ctx 中的变量, ctx 未实现 VariableStore 接口时返回 nil
+func varsOf(ctx blocks.Context) map[string]string {
+	if store, ok := ctx.(VariableStore); ok {
+		return store.Vars()
+	}
+	return nil
+}
+
+// checkVarName 检查变量名称, 由字母、数字、下划线组成, 不能以数字开头
+func checkVarName(name string) error {
+	if name == "" {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	for k, r := range []rune(name) {
+		if !isVariableRune(r, k == 0) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+	return nil
+}
+
+// sortedVarNames 排序后的变量名称
+func sortedVarNames(vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// variableSuggest 补全正在输入的变量名称（$name 或者 ${name）
+// 返回 false 表示光标前不是变量
+func variableSuggest(ctx blocks.Context, partial *token) ([]*completion.Suggest, bool) {
+	if partial == nil || partial.Quote == '\'' {
+		return nil, false
+	}
+	raw := partial.Raw
+	idx := strings.LastIndex(raw, "$")
+	if idx < 0 || (idx > 0 && raw[idx-1] == '\\') {
+		return nil, false
+	}
+	input, brace := strings.CutPrefix(raw[idx+1:], "{")
+	for k, r := range []rune(input) {
+		if !isVariableRune(r, k == 0) {
+			return nil, false
+		}
+	}
+	vars := varsOf(ctx)
+	var suggests []*completion.Suggest
+	for _, name := range sortedVarNames(vars) {
+		if !strings.HasPrefix(name, input) {
+			continue
+		}
+		text := raw[:idx] + "$" + name
+		if brace {
+			text = raw[:idx] + "${" + name + "}"
+		}
+		suggests = append(suggests, &completion.Suggest{Text: text, Description: vars[name]})
+	}
+	return suggests, true
+}
+
+// varNameSuggest 补全已经定义的变量名称
+func varNameSuggest(ctx blocks.Context, args map[string]string, input string) []*completion.Suggest {
+	vars := varsOf(ctx)
+	var suggests []*completion.Suggest
+	for _, name := range sortedVarNames(vars) {
+		if strings.HasPrefix(name, input) {
+			suggests = append(suggests, &completion.Suggest{Text: name, Description: vars[name]})
+		}
+	}
+	return suggests
+}
+
+// setArgs set 命令参数
+type setArgs struct {
+	Name  string   `arg:"variable name or name=value"`
+	Value []string `arg:"value,optional"`
+}
+
+// unsetArgs unset 命令参数
+type unsetArgs struct {
+	Names []string `arg:"variable name"`
+}
+
+// newSetCommand 创建 set 命令
+// set name value 或者 set name=value 设置变量, set name 显示变量的值
+func newSetCommand() *Command {
+	return NewCommandWithFuncE("set", "set a session variable", func(ctx blocks.Context, arg *setArgs) error {
+		name, value, ok := strings.Cut(arg.Name, "=")
+		if ok || len(arg.Value) > 0 {
+			// 多个值使用空格连接
+			if len(arg.Value) > 0 {
+				if value != "" {
+					value += " "
+				}
+				value += strings.Join(arg.Value, " ")
+			}
+			return SetVar(ctx, name, value)
+		}
+		value, found := GetVar(ctx, name)
+		if !found {
+			return fmt.Errorf("variable %s not found", name)
+		}
+		ctx.Println(name + "=" + quoteArg(value))
+		return nil
+	}).ArgComplete("Name", varNameSuggest)
+}
+
+// newUnsetCommand 创建 unset 命令
+func newUnsetCommand() *Command {
+	return NewCommandWithFuncE("unset", "remove session variables", func(ctx blocks.Context, arg *unsetArgs) error {
+		for _, name := range arg.Names {
+			if err := UnsetVar(ctx, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).ArgComplete("Names", varNameSuggest)
+}
+
+// newVarsCommand 创建 vars 命令, 列出所有变量
+func newVarsCommand() *Command {
+	return NewCommandWithFuncLegacy("vars", "list session variables", func(ctx blocks.Context) {
+		vars := varsOf(ctx)
+		for _, name := range sortedVarNames(vars) {
+			ctx.Println(name + "=" + quoteArg(vars[name]))
+		}
+	})
+}
+
+// GetVar 返回变量的值（实现 VariableStore 接口）
+func (p *promptx) GetVar(name string) (string, bool) {
+	value, ok := p.vars[name]
+	return value, ok
+}
+
+// SetVar 设置变量（实现 VariableStore 接口）
+func (p *promptx) SetVar(name, value string) error {
+	if err := checkVarName(name); err != nil {
+		return err
+	}
+	p.vars[name] = value
+	return nil
+}
+
+// UnsetVar 删除变量（实现 VariableStore 接口）
+func (p *promptx) UnsetVar(name string) error {
+	if _, ok := p.vars[name]; !ok {
+		return fmt.Errorf("variable %s not found", name)
+	}
+	delete(p.vars, name)
+	return nil
+}
+
+// Vars 返回所有变量（实现 VariableStore 接口）
+func (p *promptx) Vars() map[string]string {
+	return p.vars
+}
@@ -0,0 +1,163 @@
+package promptx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+func TestLexLineVars(t *testing.T) {
+	vars := map[string]string{
+		"server": "dev-1",
+		"user":   "alice smith",
+		"op":     "|",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+	var scenarioTable = []struct {
+		line     string
+		expected []string
+	}{
+		{line: "login $server", expected: []string{"login", "dev-1"}},
+		{line: "login ${server}-2 x$server", expected: []string{"login", "dev-1-2", "xdev-1"}},
+		{line: "login $user", expected: []string{"login", "alice smith"}},
+		{line: `echo "$user!" '$user' \$user`, expected: []string{"echo", "alice smith!", "$user", "$user"}},
+		{line: "echo $op x", expected: []string{"echo", "|", "x"}},
+		// 引号外展开为空的参数丢弃, 引号内保留为空参数
+		{line: "echo $missing end", expected: []string{"echo", "end"}},
+		{line: "echo ${missing}$missing x", expected: []string{"echo", "x"}},
+		{line: `echo "$missing" x$missing ''$missing end`, expected: []string{"echo", "", "x", "", "end"}},
+		{line: "echo $ $1 ${} ${x", expected: []string{"echo", "$", "$1", "${}", "${x"}},
+		{line: "echo 10$", expected: []string{"echo", "10$"}},
+	}
+	for _, s := range scenarioTable {
+		result := lexLineVars(s.line, lookup)
+		if err := result.err(); err != nil {
+			t.Errorf("%q: unexpected error %v", s.line, err)
+			continue
+		}
+		if actual := result.values(); !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %q, but got %q", s.line, s.expected, actual)
+		}
+		for _, tok := range result.tokens {
+			if tok.Op != "" {
+				t.Errorf("%q: variable value should not be operator", s.line)
+			}
+		}
+	}
+	// 不展开变量
+	if actual := lexLine("echo $server").values(); !reflect.DeepEqual(actual, []string{"echo", "$server"}) {
+		t.Errorf("lexLine should not expand variables, but got %q", actual)
+	}
+}
+
+func TestVarCommand(t *testing.T) {
+	var out bytes.Buffer
+	cfg := NewConfig()
+	cfg.Hardware().
+		InputParser(testutil.NoTerminalParser{}).
+		OutputWriter(output.NewConsoleWriter(&out))
+	cfg.DefaultCommandGroup().VarCommand().AliasCommand().AddCommand(
+		NewCommandWithFunc("hello", "say hello", func(ctx Context, arg *scriptTestArgs) {
+			ctx.Println("hello", arg.Name)
+		}),
+	)
+	p := cfg.Build()
+
+	err := p.RunArgs([]string{
+		"set name alice",
+		"set greeting='good morning'",
+		"hello $name",
+		`hello "${greeting}, $name"`,
+		"alias hi='hello $name'",
+		"set name bob",
+		"hi",
+		"set name",
+		"unset greeting",
+		"vars",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "hello alice\nhello good morning, alice\nhello bob\nname=bob\nname=bob\n"
+	if out.String() != expected {
+		t.Errorf("output should be %q, but got %q", expected, out.String())
+	}
+	if v, ok := GetVar(p, "name"); !ok || v != "bob" {
+		t.Errorf("GetVar should return bob, but got %q %v", v, ok)
+	}
+	// 值中的逗号原样保存
+	out.Reset()
+	if err = p.RunArgs([]string{`set x "a, b"`, "set x", "set y=a,b c", "set y"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if v, _ := GetVar(p, "x"); v != "a, b" {
+		t.Errorf("x should be %q, but got %q", "a, b", v)
+	}
+	if out.String() != "x='a, b'\ny='a,b c'\n" {
+		t.Errorf("output should be %q, but got %q", "x='a, b'\ny='a,b c'\n", out.String())
+	}
+	if err = p.RunArgs([]string{"unset x y"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err = p.RunArgs([]string{"set 1x=a"}); err == nil {
+		t.Errorf("invalid variable name should return error")
+	}
+	if err = p.RunArgs([]string{"unset greeting"}); err == nil {
+		t.Errorf("unset unknown variable should return error")
+	}
+
+	// 补全变量名称
+	var scenarioTable = []struct {
+		text     string
+		expected []string
+	}{
+		{text: "hello $", expected: []string{"$name"}},
+		{text: "hello x${na", expected: []string{"x${name}"}},
+		{text: "hello $x", expected: nil},
+		{text: "hello '$", expected: nil},
+		{text: "unset n", expected: []string{"name"}},
+	}
+	root := p.(*promptx).root
+	for _, s := range scenarioTable {
+		var texts []string
+		for _, v := range root.FindSuggestWithContext(p, *buffer.NewDocumentWithCursor(s.text, len(s.text))) {
+			texts = append(texts, v.Text)
+		}
+		if !reflect.DeepEqual(texts, s.expected) {
+			t.Errorf("%q: suggests should be %q, but got %q", s.text, s.expected, texts)
+		}
+	}
+}
+
+func TestCanceledTaskContext(t *testing.T) {
+	var out bytes.Buffer
+	p := newScriptTestPromptx(&out, true).(*promptx)
+	ctx, cancel := context.WithCancel(context.Background())
+	task := &commandTaskContext{promptx: p, ctx: ctx}
+	if err := SetVar(task, "name", "alice"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// 命令被取消后不能再修改状态以及交互输入
+	cancel()
+	if err := SetVar(task, "name", "bob"); !errors.Is(err, context.Canceled) {
+		t.Errorf("set var should fail after canceled, but got %v", err)
+	}
+	if v, _ := GetVar(p, "name"); v != "alice" {
+		t.Errorf("var should not be changed, but got %q", v)
+	}
+	if err := SetAlias(task, "hi", "hello"); !errors.Is(err, context.Canceled) {
+		t.Errorf("set alias should fail after canceled, but got %v", err)
+	}
+	if _, err := task.RawInput("name:"); !errors.Is(err, context.Canceled) {
+		t.Errorf("raw input should fail after canceled, but got %v", err)
+	}
+}
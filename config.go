@@ -2,6 +2,7 @@ package promptx
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/aggronmagi/promptx/v2/blocks"
 	"github.com/aggronmagi/promptx/v2/completion"
@@ -22,6 +23,14 @@ type PromptxConfigs struct {
 	stderrWriter output.ConsoleWriter
 	// 命令相关配置
 	commandGroups map[string]*Command
+	// 脚本模式下遇到失败的命令时继续执行
+	continueOnError bool
+	// 所有命令组共享历史记录
+	sharedHistory bool
+	// 按键绑定的编辑动作
+	binds []blocks.ActionBind
+	// 配置错误, Run 时返回
+	errs []error
 }
 
 // NewConfig 创建并返回一个新的Promptx链式配置器
@@ -66,6 +75,25 @@ func (c *PromptxConfigs) BuildBlocksOptions() []blocks.BlocksOption {
 	return opts
 }
 
+// historyFile 历史记录文件, 未设置时使用默认命令组的历史记录文件
+func (c *PromptxConfigs) historyFile() string {
+	if history := blocks.NewCommonOptions(c.common...).History; history != "" {
+		return history
+	}
+	if group, ok := c.commandGroups[""]; ok && group.config != nil {
+		return group.config.history
+	}
+	return ""
+}
+
+// addBinds 添加按键绑定. 选项设置完整的绑定列表, 后添加的选项覆盖之前的
+func (c *PromptxConfigs) addBinds(binds ...blocks.ActionBind) {
+	c.binds = append(c.binds, binds...)
+	all := slices.Clone(c.binds)
+	c.common = append(c.common, blocks.WithCommonOptionBinds(all...))
+	c.input = append(c.input, blocks.WithInputOptionBinds(all...))
+}
+
 // Build 根据当前配置构建并返回Promptx实例
 func (c *PromptxConfigs) Build() Promptx {
 	return newPromptx(c)
@@ -113,6 +141,12 @@ func (c *PromptxConfigs) Hardware() *HardwareConfig {
 	return &HardwareConfig{inner: c}
 }
 
+// Script 返回脚本模式配置器
+// 用于配置 RunScript/RunArgs 的行为
+func (c *PromptxConfigs) Script() *ScriptConfig {
+	return &ScriptConfig{inner: c}
+}
+
 // Commands 返回命令配置器
 // 用于配置可执行的命令
 func (c *PromptxConfigs) DefaultCommandGroup() *CommandGroupConfig {
@@ -158,6 +192,35 @@ func (k *KeysConfig) Select() *SelectKeysConfig {
 	return &SelectKeysConfig{inner: k.inner}
 }
 
+// Bind 绑定按键到编辑动作, 同时作用于命令行和输入框, 替换按键原有的功能
+// 动作名称与 readline 一致, 如 beginning-of-line, kill-word, history-search-backward, complete
+// 查看 blocks.ActionNames(), 自定义动作使用 blocks.RegisterAction 注册
+// 未知的动作不绑定, 错误由 Run 返回. 自定义动作需要在 Bind 之前注册
+func (k *KeysConfig) Bind(key Key, action string) *KeysConfig {
+	if !blocks.HasAction(action) {
+		k.inner.errs = append(k.inner.errs, fmt.Errorf("bind %s: %w %q", key, blocks.ErrUnknownAction, action))
+		return k
+	}
+	k.inner.addBinds(blocks.ActionBind{Key: key, Action: action})
+	return k
+}
+
+// LoadInputrc 加载 readline inputrc 格式的按键绑定文件
+// 支持 "set editing-mode vi|emacs" 以及按键绑定和宏, 与 readline 一致, 未知动作或不支持的按键的行跳过,
+// 通过 warnings 返回, 其它绑定仍然生效. 读取失败或者语法错误返回 err
+// "$if mode=" 根据当前配置的编辑模式判断, 需要在 Common().EditMode 之后调用
+func (k *KeysConfig) LoadInputrc(file string) (warnings []error, err error) {
+	rc, err := blocks.LoadInputrc(file, blocks.NewCommonOptions(k.inner.common...).EditMode)
+	if err != nil {
+		return nil, err
+	}
+	if mode, ok := rc.EditMode(); ok {
+		k.inner.Common().EditMode(mode)
+	}
+	k.inner.addBinds(rc.Binds...)
+	return rc.Warnings, nil
+}
+
 // CommonKeysConfig 通用快捷键配置器
 type CommonKeysConfig struct {
 	inner *PromptxConfigs
@@ -175,6 +238,12 @@ func (c *CommonKeysConfig) Cancel(key Key) *CommonKeysConfig {
 	return c
 }
 
+// Redo 设置通用重做键, 默认为 Meta+Shift+z. 撤销键为 Ctrl+_
+func (c *CommonKeysConfig) Redo(key Key) *CommonKeysConfig {
+	c.inner.common = append(c.inner.common, blocks.WithCommonOptionRedo(key))
+	return c
+}
+
 // InputKeysConfig 输入框快捷键配置器
 type InputKeysConfig struct {
 	inner *PromptxConfigs
@@ -192,6 +261,12 @@ func (i *InputKeysConfig) Cancel(key Key) *InputKeysConfig {
 	return i
 }
 
+// Redo 设置输入框重做键, 默认为 Meta+Shift+z. 撤销键为 Ctrl+_
+func (i *InputKeysConfig) Redo(key Key) *InputKeysConfig {
+	i.inner.input = append(i.inner.input, blocks.WithInputOptionRedo(key))
+	return i
+}
+
 // SelectKeysConfig 选择器快捷键配置器
 type SelectKeysConfig struct {
 	inner *PromptxConfigs
@@ -681,6 +756,14 @@ func (c *CommonConfig) Prefix(prefix string) *CommonConfig {
 	return c
 }
 
+// EditMode 设置编辑模式, 默认为 blocks.EditModeEmacs. 同时作用于命令行和输入框
+// blocks.EditModeVi 使用 vi 按键, 在前缀后显示当前模式 [I]/[N]/[V]
+func (c *CommonConfig) EditMode(mode blocks.EditMode) *CommonConfig {
+	c.inner.common = append(c.inner.common, blocks.WithCommonOptionEditMode(mode))
+	c.inner.input = append(c.inner.input, blocks.WithInputOptionEditMode(mode))
+	return c
+}
+
 // // Valid 设置通用验证函数
 // func (c *CommonConfig) Valid(validator func(status int, in *Document) error) *CommonConfig {
 // 	c.inner.common = append(c.inner.common, blocks.WithCommonOptionValid(validator))
@@ -699,12 +782,25 @@ func (c *CommonConfig) History(file string) *CommonConfig {
 	return c
 }
 
+// SharedHistory 设置所有命令组共享历史记录, 默认为 false
+// 共享时忽略命令组的 CommandHistory, 使用 History 设置的文件（未设置时使用默认命令组的历史记录文件）
+func (c *CommonConfig) SharedHistory(shared bool) *CommonConfig {
+	c.inner.sharedHistory = shared
+	return c
+}
+
 // HistoryMaxSize 设置历史记录最大大小
 func (c *CommonConfig) HistoryMaxSize(size int) *CommonConfig {
 	c.inner.common = append(c.inner.common, blocks.WithCommonOptionHistoryMaxSize(size))
 	return c
 }
 
+// HistorySearchFuzzy 设置 Ctrl-R 查找历史记录时按顺序匹配字符, 默认为 false 匹配子串
+func (c *CommonConfig) HistorySearchFuzzy(fuzzy bool) *CommonConfig {
+	c.inner.common = append(c.inner.common, blocks.WithCommonOptionHistorySearchFuzzy(fuzzy))
+	return c
+}
+
 // Complete 设置自动补全选项
 func (c *CommonConfig) Complete(options ...blocks.CompleteOption) *CommonConfig {
 	c.inner.common = append(c.inner.common, blocks.WithCommonOptionComplete(options...))
@@ -740,6 +836,17 @@ func (h *HardwareConfig) Manager(manager blocks.BlocksManager) *HardwareConfig {
 	return h
 }
 
+// ScriptConfig 脚本模式配置器
+type ScriptConfig struct {
+	inner *PromptxConfigs
+}
+
+// StopOnError 设置遇到失败的命令时是否停止执行脚本, 默认为 true
+func (s *ScriptConfig) StopOnError(stop bool) *ScriptConfig {
+	s.inner.continueOnError = !stop
+	return s
+}
+
 // CommandGroupConfig 命令配置器
 type CommandGroupConfig struct {
 	inner *PromptxConfigs
@@ -754,6 +861,14 @@ func (c *CommandGroupConfig) AddCommander(cmders ...Commander) *CommandGroupConf
 	return c
 }
 
+// AddCommanderE 添加返回错误的自定义命令
+func (c *CommandGroupConfig) AddCommanderE(cmders ...CommanderE) *CommandGroupConfig {
+	for _, cmd := range cmders {
+		c.group.subCommands = append(c.group.subCommands, NewCommandE(cmd))
+	}
+	return c
+}
+
 func (c *CommandGroupConfig) AddCommand(cmds ...*Command) *CommandGroupConfig {
 	for _, cmd := range cmds {
 		c.group.subCommands = append(c.group.subCommands, cmd)
@@ -761,6 +876,27 @@ func (c *CommandGroupConfig) AddCommand(cmds ...*Command) *CommandGroupConfig {
 	return c
 }
 
+// HelpCommand 添加内置的 help 命令
+// help 输出命令组中所有命令的用法, help <command...> 输出指定命令的详细用法
+func (c *CommandGroupConfig) HelpCommand() *CommandGroupConfig {
+	c.group.subCommands = append(c.group.subCommands, newHelpCommand(c.group))
+	return c
+}
+
+// AliasCommand 添加内置的 alias/unalias 命令
+// 别名在所有命令组中生效, 设置了历史记录文件时保存在历史记录文件旁边（<history>.alias）
+func (c *CommandGroupConfig) AliasCommand() *CommandGroupConfig {
+	c.group.subCommands = append(c.group.subCommands, newAliasCommand(), newUnaliasCommand())
+	return c
+}
+
+// VarCommand 添加内置的 set/unset/vars 命令
+// 变量在所有命令组中生效, 命令行中的 $name 、 ${name} 替换为变量的值
+func (c *CommandGroupConfig) VarCommand() *CommandGroupConfig {
+	c.group.subCommands = append(c.group.subCommands, newSetCommand(), newUnsetCommand(), newVarsCommand())
+	return c
+}
+
 // CommandPrompt 设置命令组提示文字
 func (c *CommandGroupConfig) CommandPrompt(prefix string) *CommandGroupConfig {
 	c.group.config.prompt = prefix
@@ -768,12 +904,14 @@ func (c *CommandGroupConfig) CommandPrompt(prefix string) *CommandGroupConfig {
 }
 
 // CommandHistory 设置命令组历史记录文件路径
+// 切换命令组时保存当前命令组的历史记录, 恢复目标命令组的历史记录. 未设置时与默认命令组共享历史记录
 func (c *CommandGroupConfig) CommandHistory(history string) *CommandGroupConfig {
 	c.group.config.history = history
 	return c
 }
 
 // CommandOnChange 设置命令组切换回调函数
+// 回调的参数为: 命令组名称, CommandGroupEvent, PushCommandGroup 传入的状态
 func (c *CommandGroupConfig) CommandOnChange(fn func(ctx blocks.Context, args ...interface{})) *CommandGroupConfig {
 	c.group.config.onChange = fn
 	return c
@@ -792,7 +930,23 @@ func (c *CommandGroupConfig) CommandPrefix(prefix string) *CommandGroupConfig {
 }
 
 // OnNonCommand 设置非命令处理函数
+// 第一个单词是命令时, 命令行的语法错误直接报告, 不调用此函数
 func (c *CommandGroupConfig) OnNonCommand(handler func(ctx blocks.Context, command string) error) *CommandGroupConfig {
 	c.group.config.onNonCommand = handler
 	return c
 }
+
+// OnCommandError 设置命令执行失败的处理函数
+// 参数错误、命令返回的错误、命令 panic 以及命令不存在时调用, 用于统一输出错误信息.
+// 参数错误可以通过 errors.As 获取 *UsageError
+func (c *CommandGroupConfig) OnCommandError(handler func(ctx blocks.Context, line string, err error)) *CommandGroupConfig {
+	c.group.config.onCommandError = handler
+	return c
+}
+
+// Use 添加命令中间件, 对命令组中的所有命令生效
+// 命令组的中间件在命令自身的中间件外层, 按照添加的顺序执行
+func (c *CommandGroupConfig) Use(mw ...Middleware) *CommandGroupConfig {
+	c.group.config.middlewares = append(c.group.config.middlewares, mw...)
+	return c
+}
@@ -0,0 +1,73 @@
+package promptx
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+func TestKeysBindUnknownAction(t *testing.T) {
+	var out bytes.Buffer
+	cfg := NewConfig()
+	cfg.Hardware().
+		InputParser(testutil.NoTerminalParser{}).
+		OutputWriter(output.NewConsoleWriter(&out))
+	// 拼写错误的动作不绑定, 由 Run 返回错误
+	cfg.Keys().
+		Bind(ControlT, "transpose-char").
+		Bind(MetaD, "kill-word")
+	if !reflect.DeepEqual(cfg.binds, []blocks.ActionBind{{Key: MetaD, Action: "kill-word"}}) {
+		t.Errorf("only known action should be bound, but got %v", cfg.binds)
+	}
+	cfg.DefaultCommandGroup().AddCommand(
+		NewCommandWithFunc("hello", "say hello", func(ctx Context, arg *scriptTestArgs) {
+			ctx.Println("hello", arg.Name)
+		}),
+	)
+	p := cfg.Build()
+	err := p.Run()
+	if !errors.Is(err, blocks.ErrUnknownAction) || !strings.Contains(err.Error(), `"transpose-char"`) {
+		t.Errorf("Run should report unknown action, but got %v", err)
+	}
+	if err := p.RunArgs([]string{"hello bob"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out.String() != "hello bob\n" {
+		t.Errorf("output should be %q, but got %q", "hello bob\n", out.String())
+	}
+}
+
+func TestKeysLoadInputrc(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "inputrc")
+	content := "set editing-mode vi\n\"\\C-x\\C-r\": re-read-init-file\nTAB: menu-complete\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig()
+	// 跳过的行通过 warnings 返回, 其它设置仍然生效
+	warnings, err := cfg.Keys().LoadInputrc(file)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], blocks.ErrUnsupportedKey) {
+		t.Errorf("should warn unsupported key, but got %v", warnings)
+	}
+	if !reflect.DeepEqual(cfg.binds, []blocks.ActionBind{{Key: Tab, Action: blocks.ActionMenuComplete}}) {
+		t.Errorf("binds should be loaded, but got %v", cfg.binds)
+	}
+	if mode := blocks.NewCommonOptions(cfg.common...).EditMode; mode != blocks.EditModeVi {
+		t.Errorf("edit mode should be vi, but got %v", mode)
+	}
+
+	if _, err = cfg.Keys().LoadInputrc(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("missing file should return error")
+	}
+}
@@ -6,6 +6,11 @@ type WinSize struct {
 	Col int
 }
 
+// defaultWinSize returns the window size used when it can not be read from terminal.
+func defaultWinSize() *WinSize {
+	return &WinSize{Row: 24, Col: 80}
+}
+
 // key 映射
 var keyMap map[uint64]Key
 
@@ -6,6 +6,7 @@ import (
 	"syscall"
 
 	"github.com/aggronmagi/promptx/v2/internal/term"
+	"github.com/pkg/term/termios"
 	"golang.org/x/sys/unix"
 )
 
@@ -15,10 +16,15 @@ const maxReadBytes = 1024
 type PosixParser struct {
 	fd          int
 	origTermios syscall.Termios
+	// error of opening /dev/tty, fd is -1 when it is not nil
+	openErr error
 }
 
 // Setup should be called before starting input
 func (t *PosixParser) Setup() error {
+	if t.openErr != nil {
+		return t.openErr
+	}
 	// Set NonBlocking mode because if syscall.Read block this goroutine, it cannot receive data from stopCh.
 	if err := syscall.SetNonblock(t.fd, true); err != nil {
 		return err
@@ -42,6 +48,9 @@ func (t *PosixParser) TearDown() error {
 
 // Read returns byte array.
 func (t *PosixParser) Read() ([]byte, error) {
+	if t.openErr != nil {
+		return []byte{}, t.openErr
+	}
 	buf := make([]byte, maxReadBytes)
 	n, err := syscall.Read(t.fd, buf)
 	if err != nil {
@@ -52,9 +61,12 @@ func (t *PosixParser) Read() ([]byte, error) {
 
 // GetWinSize returns WinSize object to represent width and height of terminal.
 func (t *PosixParser) GetWinSize() *WinSize {
+	if t.openErr != nil {
+		return defaultWinSize()
+	}
 	ws, err := unix.IoctlGetWinsize(t.fd, unix.TIOCGWINSZ)
 	if err != nil {
-		panic(err)
+		return defaultWinSize()
 	}
 	return &WinSize{
 		Row: int(ws.Row),
@@ -62,13 +74,27 @@ func (t *PosixParser) GetWinSize() *WinSize {
 	}
 }
 
+// IsTerminal reports whether the parser is attached to a terminal.
+func (t *PosixParser) IsTerminal() bool {
+	if t.openErr != nil {
+		return false
+	}
+	_, err := termios.Tcgetattr(uintptr(t.fd))
+	return err == nil
+}
+
 var _ ConsoleParser = &PosixParser{}
 
 // NewStandardInputParser returns ConsoleParser object to read from stdin.
+// If no terminal is attached, the parser still can be created, but Setup and Read
+// return the error and IsTerminal returns false.
 func NewStandardInputParser() *PosixParser {
 	in, err := syscall.Open("/dev/tty", syscall.O_RDONLY, 0)
 	if err != nil {
-		panic(err)
+		return &PosixParser{
+			fd:      -1,
+			openErr: err,
+		}
 	}
 
 	return &PosixParser{
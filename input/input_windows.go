@@ -73,13 +73,13 @@ func (p *WindowsParser) GetWinSize() *WinSize {
 		var err error
 		t, err = tty.Open()
 		if err != nil {
-			panic(err)
+			return defaultWinSize()
 		}
 		defer t.Close()
 	}
 	w, h, err := t.Size()
 	if err != nil {
-		panic(err)
+		return defaultWinSize()
 	}
 	return &WinSize{
 		Row: int(h),
@@ -87,6 +87,19 @@ func (p *WindowsParser) GetWinSize() *WinSize {
 	}
 }
 
+// IsTerminal reports whether the parser is attached to a terminal.
+func (p *WindowsParser) IsTerminal() bool {
+	if p.tty != nil {
+		return true
+	}
+	t, err := tty.Open()
+	if err != nil {
+		return false
+	}
+	t.Close()
+	return true
+}
+
 // NewStandardInputParser returns ConsoleParser object to read from stdin.
 func NewStandardInputParser() *WindowsParser {
 	return &WindowsParser{}
@@ -0,0 +1,21 @@
+// Package testutil helpers shared by the tests of packages.
+package testutil
+
+import (
+	"errors"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+// ErrNoTTY returned by NoTerminalParser
+var ErrNoTTY = errors.New("no tty")
+
+// NoTerminalParser console parser without terminal, like the input is redirected.
+// Setup and Read always fail.
+type NoTerminalParser struct{}
+
+func (NoTerminalParser) Setup() error               { return ErrNoTTY }
+func (NoTerminalParser) TearDown() error            { return nil }
+func (NoTerminalParser) GetWinSize() *input.WinSize { return &input.WinSize{Row: 24, Col: 80} }
+func (NoTerminalParser) Read() ([]byte, error)      { return nil, ErrNoTTY }
+func (NoTerminalParser) IsTerminal() bool           { return false }
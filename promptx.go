@@ -1,11 +1,16 @@
 package promptx
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/aggronmagi/promptx/v2/blocks"
 	"github.com/aggronmagi/promptx/v2/buffer"
+	"github.com/aggronmagi/promptx/v2/input"
 )
 
 type Context = blocks.Context
@@ -14,7 +19,13 @@ type Promptx interface {
 	blocks.Context
 	blocks.Controler
 	CommandGroupSwitcher
+	CommandGroupNavigator
+	CommandStatus
+	ScriptRunner
+	// Run 进入交互模式. 配置存在错误（如 Keys().Bind 绑定了未知的动作）时返回错误
 	Run() error
+	// RunOrExec 命令行参数不为空时执行一个命令并返回执行状态, 否则进入交互模式
+	RunOrExec(args []string) int
 }
 
 // CommandGroupSwitcher 命令组切换接口
@@ -54,17 +65,57 @@ type promptx struct {
 	groups map[string]*Command
 	// 根命令（用于当前命令组）
 	root *Command
+	// 上一个命令的执行状态
+	status int
+	// 上一个命令的错误
+	lastErr error
+	// 终端输入
+	input input.ConsoleParser
+	// 是否在脚本模式下执行
+	batch bool
+	// 脚本模式下遇到失败的命令时停止
+	stopOnError bool
+	// 运行时定义的别名
+	aliases map[string]string
+	// 别名文件
+	aliasFile string
+	// 会话变量
+	vars map[string]string
+	// 命令组导航栈, 栈顶为当前命令组
+	stack []*groupLevel
+	// 默认的历史记录文件
+	historyFile string
+	// 所有命令组共享历史记录
+	sharedHistory bool
+	// 配置错误
+	configErr error
 }
 
 var _ blocks.Context = &promptx{}
 var _ CommandGroupSwitcher = &promptx{}
 var _ DynamicAddCommander = &promptx{}
+var _ CommandStatus = &promptx{}
+var _ ScriptRunner = &promptx{}
+var _ AliasManager = &promptx{}
+var _ VariableStore = &promptx{}
+var _ CommandGroupNavigator = &promptx{}
 
 // New 创建新的 Promptx 实例
 func newPromptx(c *PromptxConfigs) *promptx {
 	p := &promptx{
-		groups: make(map[string]*Command),
+		groups:        make(map[string]*Command),
+		input:         c.inputParser,
+		stopOnError:   !c.continueOnError,
+		vars:          make(map[string]string),
+		historyFile:   c.historyFile(),
+		sharedHistory: c.sharedHistory,
+		configErr:     errors.Join(c.errs...),
 	}
+	if p.input == nil {
+		p.input = input.NewStandardInputParser()
+	}
+	p.aliasFile = aliasFileOf(p.historyFile)
+	p.aliases, _ = loadAliases(p.aliasFile)
 
 	c.common = append(c.common, blocks.WithCommonOptionExec(func(ctx blocks.Context, command string) {
 		p.execCommand(ctx, command)
@@ -79,9 +130,7 @@ func newPromptx(c *PromptxConfigs) *promptx {
 	if c.manager != nil {
 		options = append(options, blocks.WithManager(c.manager))
 	}
-	if c.inputParser != nil {
-		options = append(options, blocks.WithInput(c.inputParser))
-	}
+	options = append(options, blocks.WithInput(p.input))
 	if c.outputWriter != nil {
 		options = append(options, blocks.WithOutput(c.outputWriter))
 	}
@@ -110,12 +159,22 @@ func newPromptx(c *PromptxConfigs) *promptx {
 
 	// 设置初始补全
 	if p.root != nil {
+		p.stack = []*groupLevel{{name: p.root.name, group: p.root}}
+		p.SwitchHistoryFile(p.groupHistory(p.root))
 		p.setupCompletion()
 	}
 
 	return p
 }
 
+// Run 进入交互模式, 配置存在错误时直接返回错误
+func (p *promptx) Run() error {
+	if p.configErr != nil {
+		return p.configErr
+	}
+	return p.Application.Run()
+}
+
 // execCommand 执行命令
 func (p *promptx) execCommand(ctx blocks.Context, command string) {
 	if len(command) == 0 {
@@ -126,6 +185,7 @@ func (p *promptx) execCommand(ctx blocks.Context, command string) {
 	if p.root.config != nil && p.root.config.preCheck != nil {
 		if err := p.root.config.preCheck(ctx); err != nil {
 			ctx.Printf("precheck failed, %v\n", err)
+			p.setStatus(err)
 			return
 		}
 	}
@@ -140,64 +200,130 @@ func (p *promptx) execCommand(ctx blocks.Context, command string) {
 			execText = strings.TrimSpace(execText)
 		}
 	}
+	if isCmd {
+		execText = expandAliases(execText, p.aliases)
+	}
 
-	find := false
+	var (
+		list commandList
+		err  error
+	)
 	if isCmd {
-		// 执行命令，返回是否找到命令
-		find = execCommand(ctx, p.root, execText)
+		// 解析命令序列（包含管道和重定向）, 检查是否找到命令
+		list, err = parseCommandList(p.root, execText, p.GetVar)
+	}
+	if err == nil && list.found() {
+		p.runCommandList(ctx, list)
+		return
 	}
 
-	if !find {
+	switch {
+	case err != nil && startsWithCommand(p.root, execText):
+		// 命令的语法错误, 直接报告
+	case p.root.config != nil && p.root.config.onNonCommand != nil:
 		// 不是命令，调用 OnNonCommand
-		if p.root.config != nil && p.root.config.onNonCommand != nil {
-			if err := p.root.config.onNonCommand(ctx, command); err != nil {
-				ctx.Printf("%v\n", err)
-			}
-		} else {
-			ctx.Printf("command set deal functions. %s\n", command)
+		err = p.root.config.onNonCommand(ctx, command)
+	case err == nil:
+		err = fmt.Errorf("%w: %s", ErrCommandNotFound, command)
+	}
+
+	p.setStatus(err)
+	if err != nil {
+		p.onCommandError(ctx, command, err)
+	}
+}
+
+// runCommandList 依次执行命令序列, 执行状态为最后一个执行的命令的状态
+func (p *promptx) runCommandList(ctx blocks.Context, list commandList) {
+	list.run(func(item *commandListItem) error {
+		// 执行命令. 执行期间按下 Ctrl-C 取消命令的 context 并立即返回
+		err := p.RunTask(func(taskCtx context.Context) error {
+			return item.pipeline.run(taskCtx, func(in io.Reader, out io.Writer) blocks.Context {
+				return &commandTaskContext{promptx: p, ctx: taskCtx, stdin: in, stdout: out}
+			})
+		})
+		p.setStatus(err)
+		if err != nil {
+			p.onCommandError(ctx, item.line, err)
 		}
+		return err
+	})
+}
+
+// onCommandError 处理命令执行失败, 未设置 OnCommandError 时使用默认输出
+func (p *promptx) onCommandError(ctx blocks.Context, line string, err error) {
+	if p.root.config != nil && p.root.config.onCommandError != nil {
+		p.root.config.onCommandError(ctx, line, err)
+		return
 	}
+	printCommandError(ctx, line, err)
+}
+
+// setStatus 记录命令执行状态
+func (p *promptx) setStatus(err error) {
+	p.status = statusOf(err)
+	p.lastErr = err
+}
+
+// LastStatus 返回上一个命令的执行状态（实现 CommandStatus 接口）
+func (p *promptx) LastStatus() int {
+	return p.status
+}
+
+// LastError 返回上一个命令的错误（实现 CommandStatus 接口）
+func (p *promptx) LastError() error {
+	return p.lastErr
 }
 
 // SwitchCommandGroup 切换命令组（实现 CommandGroupSwitcher 接口）
+// 切换命令组会清空导航栈, 通过 PushCommandGroup 进入的命令组依次触发离开事件
 func (p *promptx) SwitchCommandGroup(name string) error {
 	group, ok := p.groups[name]
 	if !ok {
 		return fmt.Errorf("command group %s not found", name)
 	}
 
-	// 切换 history
-	if group.config != nil && group.config.history != "" {
-		p.ResetHistoryFile(group.config.history)
-	} else {
-		// 共享history也只和默认命令组共享.要不然命令组的history就太乱了.
-
-		// 查找默认命令组, 切换前是默认命令组，则不修改.
-		if defaultGroup, ok := p.groups[""]; ok && defaultGroup != p.root {
-			// 默认命令组有 history 文件，则切换到默认命令组的history文件.
-			if defaultGroup.config != nil {
-				p.ResetHistoryFile(defaultGroup.config.history)
-			}
-		}
+	// 离开通过 PushCommandGroup 进入的命令组
+	for len(p.stack) > 1 {
+		level := p.stack[len(p.stack)-1]
+		p.stack = p.stack[:len(p.stack)-1]
+		p.fireGroupEvent(level, CommandGroupLeave)
 	}
 
-	// 设置 prompt
-	if group.config != nil && group.config.prompt != "" {
-		p.SetPrompt(group.config.prompt)
-	}
+	level := &groupLevel{name: name, group: group}
+	p.stack = []*groupLevel{level}
+	p.activateGroup(group)
+
+	// 调用切换回调
+	p.fireGroupEvent(level, CommandGroupEnter)
+
+	return nil
+}
+
+// activateGroup 使用命令组的 history 、 prompt 以及自动补全
+func (p *promptx) activateGroup(group *Command) {
+	// 切换 history, 保存当前命令组的历史记录
+	p.SwitchHistoryFile(p.groupHistory(group))
 
 	// 切换根命令
 	p.root = group
 
+	// 设置 prompt
+	if prompt := p.groupPrompt(); prompt != "" {
+		p.SetPrompt(prompt)
+	}
+
 	// 设置自动补全
 	p.setupCompletion()
+}
 
-	// 调用切换回调
-	if group.config != nil && group.config.onChange != nil {
-		group.config.onChange(p, name)
+// groupHistory 命令组的历史记录文件
+// 未设置 CommandHistory 或者共享历史记录时使用默认的历史记录文件
+func (p *promptx) groupHistory(group *Command) string {
+	if !p.sharedHistory && group.config != nil && group.config.history != "" {
+		return group.config.history
 	}
-
-	return nil
+	return p.historyFile
 }
 
 // setupCompletion 设置自动补全
@@ -227,7 +353,7 @@ func (p *promptx) setupCompletion() {
 		}
 
 		// 创建完成器
-		completer := createCompleter(p.root, commandPrefix)
+		completer := createCompleter(p, p.root, commandPrefix)
 
 		// 设置 Valid 函数来验证命令
 		validFunc := func(status int, doc *buffer.Document) error {
@@ -246,15 +372,16 @@ func (p *promptx) setupCompletion() {
 				text = text[len(commandPrefix):]
 				text = strings.TrimSpace(text)
 			}
-			// 解析命令检查是否存在
-			cmdCtx, err := parseCommand(p.root, text)
+			text = expandAliases(text, p.aliases)
+			// 解析命令检查是否存在, 命令序列以及管道中的每个命令都需要存在
+			list, err := parseCommandList(p.root, text, p.GetVar)
 			if err != nil {
 				return err
 			}
-			if cmdCtx == nil || cmdCtx.cur == nil || cmdCtx.cur == p.root {
+			if !list.found() {
 				return fmt.Errorf("not found command[%s]", doc.Text)
 			}
-			return nil
+			return list.check()
 		}
 
 		// 应用选项
@@ -288,3 +415,191 @@ func (p *promptx) Printf(format string, v ...interface{}) {
 func (p *promptx) Println(v ...interface{}) {
 	fmt.Fprintln(p.Stdout(), v...)
 }
+
+// RawInput 交互输入. 脚本模式下没有终端时返回 ErrNoTerminal
+func (p *promptx) RawInput(tip string, opts ...blocks.InputOption) (string, error) {
+	if err := p.checkTerminal(); err != nil {
+		return "", err
+	}
+	return p.Application.RawInput(tip, opts...)
+}
+
+// RawSelect 交互单选. 脚本模式下没有终端时返回 -1, 与取消选择相同
+func (p *promptx) RawSelect(tip string, list []string, opts ...blocks.SelectOption) int {
+	if p.checkTerminal() != nil {
+		return -1
+	}
+	return p.Application.RawSelect(tip, list, opts...)
+}
+
+// RawMulSel 交互多选. 脚本模式下没有终端时返回 nil, 与取消选择相同
+func (p *promptx) RawMulSel(tip string, list []string, opts ...blocks.SelectOption) []int {
+	if p.checkTerminal() != nil {
+		return nil
+	}
+	return p.Application.RawMulSel(tip, list, opts...)
+}
+
+// commandTaskContext 命令执行时的上下文, 使用命令自己的 context.Context
+// 命令被取消后, 即使命令仍在执行, Done 也保持关闭状态
+// 在管道中执行时, 输入输出替换为管道或者重定向的文件
+type commandTaskContext struct {
+	*promptx
+	ctx context.Context
+	// 命令的输入, nil 表示没有输入
+	stdin io.Reader
+	// 命令的输出, nil 表示输出到终端
+	stdout io.Writer
+}
+
+// Deadline 实现 context.Context
+func (c *commandTaskContext) Deadline() (deadline time.Time, ok bool) {
+	return c.ctx.Deadline()
+}
+
+// Done 实现 context.Context, 命令被取消时关闭
+func (c *commandTaskContext) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Err 实现 context.Context
+func (c *commandTaskContext) Err() error {
+	return c.ctx.Err()
+}
+
+// Value 实现 context.Context
+func (c *commandTaskContext) Value(key any) any {
+	return c.ctx.Value(key)
+}
+
+// Stdin 返回命令的输入（实现 StdinReader 接口）
+func (c *commandTaskContext) Stdin() io.Reader {
+	return c.stdin
+}
+
+// Stdout 返回命令的输出, 在管道中执行时为管道或者重定向的文件
+func (c *commandTaskContext) Stdout() io.Writer {
+	if c.stdout != nil {
+		return c.stdout
+	}
+	return c.promptx.Stdout()
+}
+
+// Print 打印到命令的输出
+func (c *commandTaskContext) Print(v ...interface{}) {
+	fmt.Fprint(c.Stdout(), v...)
+}
+
+// Printf 格式化打印到命令的输出
+func (c *commandTaskContext) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(c.Stdout(), format, v...)
+}
+
+// Println 打印到命令的输出并换行
+func (c *commandTaskContext) Println(v ...interface{}) {
+	fmt.Fprintln(c.Stdout(), v...)
+}
+
+// WPrint 打印到命令的输出. 输出到管道或者文件时忽略颜色
+func (c *commandTaskContext) WPrint(words ...*blocks.Word) {
+	if c.stdout == nil {
+		c.promptx.WPrint(words...)
+		return
+	}
+	for _, w := range words {
+		io.WriteString(c.stdout, w.Text)
+	}
+}
+
+// WPrintln 打印到命令的输出并换行. 输出到管道或者文件时忽略颜色
+func (c *commandTaskContext) WPrintln(words ...*blocks.Word) {
+	if c.stdout == nil {
+		c.promptx.WPrintln(words...)
+		return
+	}
+	c.WPrint(words...)
+	io.WriteString(c.stdout, "\n")
+}
+
+// 命令被取消后 context 失效, 仍在执行的命令不能再交互输入以及修改 promptx 的状态,
+// 避免与之后输入的命令冲突
+
+// RawInput 交互输入, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) RawInput(tip string, opts ...blocks.InputOption) (string, error) {
+	if err := c.ctx.Err(); err != nil {
+		return "", err
+	}
+	return c.promptx.RawInput(tip, opts...)
+}
+
+// RawSelect 交互单选, 命令被取消后返回 -1
+func (c *commandTaskContext) RawSelect(tip string, list []string, opts ...blocks.SelectOption) int {
+	if c.ctx.Err() != nil {
+		return -1
+	}
+	return c.promptx.RawSelect(tip, list, opts...)
+}
+
+// RawMulSel 交互多选, 命令被取消后返回 nil
+func (c *commandTaskContext) RawMulSel(tip string, list []string, opts ...blocks.SelectOption) []int {
+	if c.ctx.Err() != nil {
+		return nil
+	}
+	return c.promptx.RawMulSel(tip, list, opts...)
+}
+
+// SetVar 设置变量, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) SetVar(name, value string) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.SetVar(name, value)
+}
+
+// UnsetVar 删除变量, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) UnsetVar(name string) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.UnsetVar(name)
+}
+
+// SetAlias 设置别名, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) SetAlias(name, value string) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.SetAlias(name, value)
+}
+
+// RemoveAlias 删除别名, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) RemoveAlias(name string) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.RemoveAlias(name)
+}
+
+// SwitchCommandGroup 切换命令组, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) SwitchCommandGroup(name string) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.SwitchCommandGroup(name)
+}
+
+// PushCommandGroup 进入命令组, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) PushCommandGroup(name string, state any) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.PushCommandGroup(name, state)
+}
+
+// PopCommandGroup 返回上一级命令组, 命令被取消后返回 context 的错误
+func (c *commandTaskContext) PopCommandGroup() error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.promptx.PopCommandGroup()
+}
@@ -0,0 +1,142 @@
+package promptx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aggronmagi/promptx/v2/blocks"
+)
+
+// ErrNoTerminal 脚本模式下没有终端, 无法交互输入
+var ErrNoTerminal = errors.New("no terminal attached, can not prompt for input")
+
+// ScriptRunner 脚本执行接口
+type ScriptRunner interface {
+	// RunScript 逐行执行脚本中的命令, 不进入 raw 模式
+	RunScript(r io.Reader) error
+	// RunArgs 依次执行多行命令, 不进入 raw 模式
+	RunArgs(lines []string) error
+}
+
+// ScriptError 脚本中的命令执行失败
+type ScriptError struct {
+	// 行号, 从 1 开始
+	Line int
+	// 命令
+	Command string
+	// 命令的错误
+	Err error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Command, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// terminalChecker 检查是否可以交互输入
+type terminalChecker interface {
+	checkTerminal() error
+}
+
+// checkTerminal 检查是否可以交互输入
+// 通过接口判定，如果 ctx 未实现 terminalChecker 接口则认为可以交互输入
+func checkTerminal(ctx blocks.Context) error {
+	checker, ok := ctx.(terminalChecker)
+	if !ok {
+		return nil
+	}
+	return checker.checkTerminal()
+}
+
+// RunScript 逐行执行脚本中的命令（实现 ScriptRunner 接口）
+// 空行以及 # 开头的注释行会被忽略. 命令与交互模式使用相同的解析和执行流程.
+// 默认遇到第一个失败的命令时停止, 返回 *ScriptError. 可以通过 Script().StopOnError(false) 继续执行,
+// 此时返回第一个失败的命令的错误.
+func (p *promptx) RunScript(r io.Reader) error {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return p.RunArgs(lines)
+}
+
+// RunArgs 依次执行多行命令（实现 ScriptRunner 接口）, 规则与 RunScript 相同
+func (p *promptx) RunArgs(lines []string) error {
+	defer p.enterBatch()()
+
+	var first error
+	for k, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.execCommand(p, line)
+		if p.lastErr == nil {
+			continue
+		}
+		err := &ScriptError{Line: k + 1, Command: line, Err: p.lastErr}
+		if p.stopOnError {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// RunOrExec 命令行参数不为空时执行一个命令并返回执行状态, 否则进入交互模式
+// 参数已经由 shell 分词, 每个参数作为一个完整的值, 不再解析引号以及操作符.
+// 例如: os.Exit(p.RunOrExec(os.Args[1:]))
+func (p *promptx) RunOrExec(args []string) int {
+	if len(args) == 0 {
+		if err := p.Run(); err != nil {
+			fmt.Fprintln(p.Stderr(), err)
+			return StatusError
+		}
+		return StatusOK
+	}
+
+	defer p.enterBatch()()
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, quoteArg(arg))
+	}
+	line := strings.Join(quoted, " ")
+	if p.root.config != nil && p.root.config.commandPrefix != "" {
+		line = p.root.config.commandPrefix + line
+	}
+	p.execCommand(p, line)
+	return p.LastStatus()
+}
+
+// enterBatch 进入脚本模式, 返回恢复之前模式的函数
+func (p *promptx) enterBatch() (restore func()) {
+	batch := p.batch
+	p.batch = true
+	return func() {
+		p.batch = batch
+	}
+}
+
+// checkTerminal 脚本模式下没有终端时返回 ErrNoTerminal
+func (p *promptx) checkTerminal() error {
+	if !p.batch {
+		return nil
+	}
+	if t, ok := p.input.(interface{ IsTerminal() bool }); ok && !t.IsTerminal() {
+		return ErrNoTerminal
+	}
+	return nil
+}
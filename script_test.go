@@ -0,0 +1,177 @@
+package promptx
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aggronmagi/promptx/v2/internal/testutil"
+	"github.com/aggronmagi/promptx/v2/output"
+)
+
+type scriptTestArgs struct {
+	Name string `arg:"name"`
+}
+
+func newScriptTestPromptx(out *bytes.Buffer, stopOnError bool) Promptx {
+	cfg := NewConfig()
+	cfg.Hardware().
+		InputParser(testutil.NoTerminalParser{}).
+		OutputWriter(output.NewConsoleWriter(out))
+	cfg.Script().StopOnError(stopOnError)
+	cfg.DefaultCommandGroup().AddCommand(
+		NewCommandWithFunc("hello", "say hello", func(ctx Context, arg *scriptTestArgs) {
+			ctx.Println("hello", arg.Name)
+		}),
+		NewCommandWithFuncE("fail", "always fail", func(ctx Context, arg *statusTestArgs) error {
+			return errors.New("failed")
+		}),
+		NewCommandWithFuncLegacy("ask", "ask input", func(ctx Context) {
+			_, err := ctx.RawInput("name:")
+			ctx.Println("ask:", err)
+		}),
+	)
+	return cfg.Build()
+}
+
+func TestRunScript(t *testing.T) {
+	var out bytes.Buffer
+	p := newScriptTestPromptx(&out, true)
+	script := `
+# comment
+hello alice
+  hello 'bob smith' && hello carol
+
+hello dave | grep dave
+`
+	if err := p.RunScript(strings.NewReader(script)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "hello alice\nhello bob smith\nhello carol\nhello dave\n"
+	if out.String() != expected {
+		t.Errorf("output should be %q, but got %q", expected, out.String())
+	}
+}
+
+func TestRunScriptStopOnError(t *testing.T) {
+	var out bytes.Buffer
+	p := newScriptTestPromptx(&out, true)
+	err := p.RunArgs([]string{"hello a", "fail 1", "hello b"})
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Line != 2 || scriptErr.Command != "fail 1" {
+		t.Fatalf("should stop at line 2, but got %v", err)
+	}
+	if strings.Contains(out.String(), "hello b") {
+		t.Errorf("should not run commands after failure, output %q", out.String())
+	}
+	if p.LastStatus() != StatusError {
+		t.Errorf("status should be %d, but got %d", StatusError, p.LastStatus())
+	}
+
+	out.Reset()
+	p = newScriptTestPromptx(&out, false)
+	err = p.RunArgs([]string{"unknown", "fail 1", "hello b"})
+	if !errors.As(err, &scriptErr) || scriptErr.Line != 1 || !errors.Is(err, ErrCommandNotFound) {
+		t.Fatalf("should return first error, but got %v", err)
+	}
+	if !strings.Contains(out.String(), "hello b") {
+		t.Errorf("should continue after failure, output %q", out.String())
+	}
+}
+
+func TestRunScriptNoTerminal(t *testing.T) {
+	var out bytes.Buffer
+	p := newScriptTestPromptx(&out, true)
+	err := p.RunArgs([]string{"hello"})
+	var usageErr *UsageError
+	if !errors.Is(err, ErrNoTerminal) || !errors.As(err, &usageErr) {
+		t.Fatalf("missing argument should return ErrNoTerminal, but got %v", err)
+	}
+
+	out.Reset()
+	if err = p.RunArgs([]string{"ask"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(out.String(), "ask: "+ErrNoTerminal.Error()) {
+		t.Errorf("RawInput should return ErrNoTerminal, output %q", out.String())
+	}
+}
+
+func TestRunOrExec(t *testing.T) {
+	var scenarioTable = []struct {
+		args     []string
+		status   int
+		expected string
+	}{
+		{args: []string{"hello", "bob smith"}, status: StatusOK, expected: "hello bob smith\n"},
+		{args: []string{"hello", "a | b; c"}, status: StatusOK, expected: "hello a | b; c\n"},
+		{args: []string{"fail", "1"}, status: StatusError},
+		{args: []string{"fail", "x"}, status: StatusUsage},
+		{args: []string{"hello"}, status: StatusUsage},
+		{args: []string{"unknown"}, status: StatusNotFound},
+	}
+	for _, s := range scenarioTable {
+		var out bytes.Buffer
+		p := newScriptTestPromptx(&out, true)
+		if status := p.RunOrExec(s.args); status != s.status {
+			t.Errorf("%q: status should be %d, but got %d", s.args, s.status, status)
+		}
+		if s.expected != "" && out.String() != s.expected {
+			t.Errorf("%q: output should be %q, but got %q", s.args, s.expected, out.String())
+		}
+	}
+}
+
+func TestOnNonCommandSyntaxError(t *testing.T) {
+	var out bytes.Buffer
+	var lines []string
+	cfg := NewConfig()
+	cfg.Hardware().
+		InputParser(testutil.NoTerminalParser{}).
+		OutputWriter(output.NewConsoleWriter(&out))
+	cfg.DefaultCommandGroup().OnNonCommand(func(ctx Context, command string) error {
+		lines = append(lines, command)
+		return nil
+	}).AddCommand(
+		NewCommandWithFunc("hello", "say hello", func(ctx Context, arg *scriptTestArgs) {
+			ctx.Println("hello", arg.Name)
+		}),
+	)
+	p := cfg.Build()
+
+	// 不是命令时即使存在语法错误也交给 OnNonCommand 处理
+	if err := p.RunArgs([]string{"don't panic", "what ;; now"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(lines, []string{"don't panic", "what ;; now"}) {
+		t.Errorf("non command should be handled, but got %q", lines)
+	}
+	// 命令的语法错误直接报告
+	lines = nil
+	if err := p.RunArgs([]string{"hello 'bob"}); !errors.Is(err, ErrUnterminatedQuote) {
+		t.Errorf("should return syntax error, but got %v", err)
+	}
+	if err := p.RunArgs([]string{"hello a ;; hello b"}); err == nil {
+		t.Errorf("should return syntax error")
+	}
+	if lines != nil {
+		t.Errorf("syntax error of command should not be handled as non command, but got %q", lines)
+	}
+}
+
+func TestGrepNoMatchStatus(t *testing.T) {
+	var out bytes.Buffer
+	p := newScriptTestPromptx(&out, true)
+	// 没有匹配的行时执行状态为 StatusError, 不输出错误信息
+	if err := p.RunArgs([]string{"hello a | grep x"}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("should return ErrNoMatch, but got %v", err)
+	}
+	if p.LastStatus() != StatusError {
+		t.Errorf("status should be %d, but got %d", StatusError, p.LastStatus())
+	}
+	if out.String() != "" {
+		t.Errorf("should not print anything, but got %q", out.String())
+	}
+}
@@ -31,6 +31,11 @@ func (t *TerminalApp) handleSignals(exitCh chan int, winSizeCh chan *input.WinSi
 			switch s {
 			case syscall.SIGINT: // kill -SIGINT XXXX or Ctrl+c
 				debug.Log("Catch SIGINT")
+				// interrupt running task
+				if t.interruptTask() {
+					debug.Log("interrupt task")
+					break
+				}
 				exitCh <- 0
 
 			case syscall.SIGTERM: // kill -SIGTERM XXXX
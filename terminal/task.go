@@ -0,0 +1,60 @@
+package terminal
+
+import (
+	"context"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+// task 正在执行的任务
+type task struct {
+	cancel context.CancelFunc
+}
+
+// SetInterruptKey 设置任务执行期间取消任务的按键, 默认为 Ctrl-C
+func (t *TerminalApp) SetInterruptKey(key input.Key) {
+	t.interruptKey.Store(int32(key))
+}
+
+// StartTask 开始执行任务, 返回任务的 context.
+// 任务执行期间（当前 App 的 IsInTask 为 true）收到 SIGINT 或者中断键时取消 context.
+// 任务结束后需要调用 end.
+func (t *TerminalApp) StartTask(parent context.Context) (ctx context.Context, end func()) {
+	ctx, cancel := context.WithCancel(parent)
+	cur := &task{cancel: cancel}
+	t.taskMu.Lock()
+	t.tasks = append(t.tasks, cur)
+	t.taskMu.Unlock()
+	return ctx, func() {
+		cancel()
+		t.taskMu.Lock()
+		defer t.taskMu.Unlock()
+		for k, v := range t.tasks {
+			if v == cur {
+				t.tasks = append(t.tasks[:k], t.tasks[k+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// interruptTask 取消最近开始的任务, 返回是否取消了任务
+// 任务中打开的交互界面（例如 RawInput）不在执行任务, 按键由交互界面处理.
+func (t *TerminalApp) interruptTask() bool {
+	app := t.GetCurrentApp()
+	if app == nil || !app.IsInTask() {
+		return false
+	}
+	t.taskMu.Lock()
+	defer t.taskMu.Unlock()
+	if len(t.tasks) == 0 {
+		return false
+	}
+	t.tasks[len(t.tasks)-1].cancel()
+	return true
+}
+
+// isInterrupt 输入是否为中断键
+func (t *TerminalApp) isInterrupt(b []byte) bool {
+	return input.GetKey(b) == input.Key(t.interruptKey.Load())
+}
@@ -0,0 +1,52 @@
+package terminal
+
+import (
+	"context"
+	"testing"
+	"unsafe"
+
+	"github.com/aggronmagi/promptx/v2/input"
+)
+
+type taskTestApp struct {
+	App
+	inTask bool
+}
+
+func (a *taskTestApp) IsInTask() bool { return a.inTask }
+
+func TestInterruptTask(t *testing.T) {
+	term := NewTerminalApp(nil)
+	if term.interruptTask() {
+		t.Fatal("should not interrupt without running app")
+	}
+	app := App(&taskTestApp{})
+	term.appPtr.Store(unsafe.Pointer(&app))
+
+	ctx, end := term.StartTask(context.Background())
+	defer end()
+	if term.interruptTask() {
+		t.Fatal("should not interrupt when app is not in task")
+	}
+	app.(*taskTestApp).inTask = true
+	nested, endNested := term.StartTask(context.Background())
+	if !term.isInterrupt([]byte{0x3}) || !term.interruptTask() {
+		t.Fatal("ctrl-c should interrupt running task")
+	}
+	if nested.Err() != context.Canceled || ctx.Err() != nil {
+		t.Fatal("should only cancel the latest task")
+	}
+	endNested()
+	if !term.interruptTask() || ctx.Err() != context.Canceled {
+		t.Fatal("should cancel previous task after nested task end")
+	}
+	end()
+	if term.interruptTask() {
+		t.Fatal("should not interrupt when no task running")
+	}
+
+	term.SetInterruptKey(input.ControlG)
+	if term.isInterrupt([]byte{0x3}) {
+		t.Fatal("ctrl-c should not be interrupt key")
+	}
+}
@@ -46,10 +46,14 @@ type TerminalApp struct {
 	appPtr  atomic.UnsafePointer
 	m       sync.Mutex
 	appList list.List
+	// running tasks
+	taskMu       sync.Mutex
+	tasks        []*task
+	interruptKey atomic.Int32
 }
 
 func NewTerminalApp(in input.ConsoleParser) *TerminalApp {
-	return &TerminalApp{
+	t := &TerminalApp{
 		in:        in,
 		sizeCh:    make(chan *input.WinSize),
 		signCh:    make(chan int),
@@ -57,6 +61,8 @@ func NewTerminalApp(in input.ConsoleParser) *TerminalApp {
 		bufCh:     make(chan []byte),
 		closeRead: make(chan struct{}, 1),
 	}
+	t.SetInterruptKey(input.ControlC)
+	return t
 }
 
 func (t *TerminalApp) Run(app App) {
@@ -172,6 +178,11 @@ func (t *TerminalApp) readBuffer(bufCh chan []byte, stopCh chan struct{}) {
 			return
 		default:
 			if b, err := t.in.Read(); err == nil && !(len(b) == 1 && b[0] == 0) {
+				// interrupt running task
+				if t.isInterrupt(b) && t.interruptTask() {
+					debug.Log("interrupt task")
+					break
+				}
 				bufCh <- b
 			} else {
 				debug.Log("read error:" + err.Error())